@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+
+	openshiftclient "github.com/openshift/client-go/config/clientset/versioned"
+	mcfgclient "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openshift-cluster-check/health-checker/internal/checker"
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+)
+
+// Exit codes of the check subcommand.
+const (
+	exitHealthy   = 0 // every check is healthy
+	exitUnhealthy = 1 // at least one check found a problem
+	exitUsage     = 2 // invalid flags or configuration
+	exitUnknown   = 3 // no problem found, but at least one check could not evaluate the cluster
+)
+
+// Values for the check subcommand's --output flag.
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+)
+
+// report is the JSON document printed by the check subcommand.
+type report struct {
+	Status checker.Status   `json:"status"`
+	Checks []checker.Result `json:"checks"`
+}
+
+// runCheck implements `health-checker check --once`: it runs every enabled
+// check a single time, prints a report to stdout and returns the exit code.
+// Log lines go to stderr, so the report can be piped.
+func runCheck(args []string) int {
+	fs := flag.NewFlagSet("check", flag.ContinueOnError)
+	once := fs.Bool("once", false, "Run every enabled check once, print a report and exit. Required.")
+	output := fs.String("output", outputTable, "Report format: table or json.")
+	kubeconfig := fs.String("kubeconfig", "", "Path to a kubeconfig file. Default: in-cluster config, or $KUBECONFIG / ~/.kube/config outside a pod.")
+	kubeContext := fs.String("context", "", "Kubeconfig context to use instead of the current context.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: health-checker check --once [flags]\n\n")
+		fmt.Fprintf(fs.Output(), "Exit codes: %d healthy, %d unhealthy, %d invalid usage or configuration, %d unknown (a check could not evaluate the cluster).\n\nFlags:\n",
+			exitHealthy, exitUnhealthy, exitUsage, exitUnknown)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitHealthy
+		}
+		return exitUsage
+	}
+	if !*once {
+		fmt.Fprintln(os.Stderr, "ERROR: check requires --once (run without a subcommand to check continuously)")
+		fs.Usage()
+		return exitUsage
+	}
+	if *output != outputTable && *output != outputJSON {
+		fmt.Fprintf(os.Stderr, "ERROR: --output must be %q or %q (got %q)\n", outputTable, outputJSON, *output)
+		return exitUsage
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: invalid configuration: %v\n", err)
+		return exitUsage
+	}
+
+	restCfg, err := restConfig(*kubeconfig, *kubeContext)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: %v\n", err)
+		return exitUnknown
+	}
+	k8sClient, err := kubernetes.NewForConfig(restCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: failed to create Kubernetes client: %v\n", err)
+		return exitUnknown
+	}
+	ocpClientset, err := openshiftclient.NewForConfig(restCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: failed to create OpenShift client: %v\n", err)
+		return exitUnknown
+	}
+	mcfgClientset, err := mcfgclient.NewForConfig(restCfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: failed to create OpenShift machineconfiguration client: %v\n", err)
+		return exitUnknown
+	}
+
+	registry := checker.NewDefaultRegistry(checker.NewAPISource(k8sClient, ocpClientset.ConfigV1(), mcfgClientset.MachineconfigurationV1()))
+	if err := registry.Validate(cfg); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: invalid configuration: %v\n", err)
+		return exitUsage
+	}
+	registry.SetOperatorGroups(cfg.OperatorGroups)
+	if err := registry.SetDisabled(cfg.Disabled()); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: invalid DISABLED_CHECKS: %v\n", err)
+		return exitUsage
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	results := checker.RunChecks(ctx, registry, cfg)
+
+	if err := writeReport(os.Stdout, *output, results); err != nil {
+		fmt.Fprintf(os.Stderr, "ERROR: failed to write report: %v\n", err)
+		return exitUnknown
+	}
+	return exitCode(checker.OverallStatus(results))
+}
+
+// exitCode maps the overall status of a cycle to the check subcommand's exit code.
+func exitCode(status checker.Status) int {
+	switch status {
+	case checker.StatusHealthy:
+		return exitHealthy
+	case checker.StatusUnhealthy:
+		return exitUnhealthy
+	default:
+		return exitUnknown
+	}
+}
+
+// writeReport writes results to w as a table (one line per reason) or as JSON.
+func writeReport(w io.Writer, format string, results []checker.Result) error {
+	if format == outputJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report{Status: checker.OverallStatus(results), Checks: results})
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHECK\tSTATUS\tDETAILS")
+	for _, r := range results {
+		details := r.Reasons
+		if len(details) == 0 {
+			details = []string{"-"}
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", r.Check, r.Status, details[0])
+		for _, d := range details[1:] {
+			fmt.Fprintf(tw, "\t\t%s\n", d)
+		}
+	}
+	fmt.Fprintf(tw, "\nOverall: %s\n", strings.ToUpper(string(checker.OverallStatus(results))))
+	return tw.Flush()
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/openshift-cluster-check/health-checker/internal/checker"
+)
+
+func testResults() []checker.Result {
+	return []checker.Result{
+		{Check: "etcd", Status: checker.StatusHealthy},
+		{
+			Check:    "nodes",
+			Status:   checker.StatusUnhealthy,
+			Reasons:  []string{`Node "worker-1" is not Ready`, `Node "worker-2" is not Ready`},
+			Affected: []checker.ObjectRef{{Kind: "Node", Name: "worker-1"}, {Kind: "Node", Name: "worker-2"}},
+		},
+	}
+}
+
+func TestWriteReport_Table(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, outputTable, testResults()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"CHECK", "etcd", "healthy", `Node "worker-1" is not Ready`, `Node "worker-2" is not Ready`, "Overall: UNHEALTHY"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected table to contain %q, got:\n%s", want, out)
+		}
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeReport(&buf, outputJSON, testResults()); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	var got report
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, got: %v\n%s", err, buf.String())
+	}
+	if got.Status != checker.StatusUnhealthy || len(got.Checks) != 2 {
+		t.Fatalf("expected unhealthy report with 2 checks, got %+v", got)
+	}
+	if len(got.Checks[1].Affected) != 2 || got.Checks[1].Affected[0].Name != "worker-1" {
+		t.Errorf("expected affected nodes in the report, got %+v", got.Checks[1].Affected)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	cases := map[checker.Status]int{
+		checker.StatusHealthy:   exitHealthy,
+		checker.StatusUnhealthy: exitUnhealthy,
+		checker.StatusUnknown:   exitUnknown,
+	}
+	for status, want := range cases {
+		if got := exitCode(status); got != want {
+			t.Errorf("exitCode(%s) = %d, want %d", status, got, want)
+		}
+	}
+}
+
+func TestRunCheck_Usage(t *testing.T) {
+	if got := runCheck(nil); got != exitUsage {
+		t.Errorf("expected exit code %d without --once, got %d", exitUsage, got)
+	}
+	if got := runCheck([]string{"--once", "--output", "yaml"}); got != exitUsage {
+		t.Errorf("expected exit code %d for invalid --output, got %d", exitUsage, got)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// restConfig returns the client configuration for the cluster to check.
+//
+// With neither kubeconfig nor context given it uses the in-cluster service
+// account, and outside a pod falls back to the default kubeconfig ($KUBECONFIG
+// or ~/.kube/config). Otherwise it loads the given kubeconfig file (or the
+// default one) and, if context is non-empty, uses that context instead of the
+// file's current context.
+func restConfig(kubeconfig, context string) (*rest.Config, error) {
+	if kubeconfig == "" && context == "" {
+		cfg, err := rest.InClusterConfig()
+		if err == nil {
+			log.Println("INFO: Using in-cluster configuration.")
+			return cfg, nil
+		}
+		if !errors.Is(err, rest.ErrNotInCluster) {
+			return nil, fmt.Errorf("failed to build in-cluster config: %w", err)
+		}
+	}
+
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = kubeconfig
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: context}
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, overrides)
+
+	raw, err := clientConfig.RawConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	cfg, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build config from kubeconfig: %w", err)
+	}
+
+	current := raw.CurrentContext
+	if context != "" {
+		current = context
+	}
+	log.Printf("INFO: Using kubeconfig context %q (server %s).", current, cfg.Host)
+	return cfg, nil
+}
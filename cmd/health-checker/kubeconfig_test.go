@@ -0,0 +1,77 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: prod
+  cluster:
+    server: https://api.prod.example.com:6443
+- name: staging
+  cluster:
+    server: https://api.staging.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: prod
+  context: {cluster: prod, user: admin}
+- name: staging
+  context: {cluster: staging, user: admin}
+current-context: prod
+`
+
+func writeKubeconfig(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "kubeconfig")
+	if err := os.WriteFile(path, []byte(testKubeconfig), 0o600); err != nil {
+		t.Fatalf("failed to write kubeconfig: %v", err)
+	}
+	return path
+}
+
+func TestRestConfig_Kubeconfig(t *testing.T) {
+	cfg, err := restConfig(writeKubeconfig(t), "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.Host != "https://api.prod.example.com:6443" {
+		t.Errorf("expected the current context's server, got %q", cfg.Host)
+	}
+}
+
+func TestRestConfig_Context(t *testing.T) {
+	cfg, err := restConfig(writeKubeconfig(t), "staging")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.Host != "https://api.staging.example.com:6443" {
+		t.Errorf("expected the staging server, got %q", cfg.Host)
+	}
+}
+
+func TestRestConfig_UnknownContext(t *testing.T) {
+	if _, err := restConfig(writeKubeconfig(t), "missing"); err == nil {
+		t.Fatal("expected error for unknown context, got nil")
+	}
+}
+
+func TestRestConfig_FallsBackToKubeconfigOutsideCluster(t *testing.T) {
+	t.Setenv("KUBERNETES_SERVICE_HOST", "")
+	t.Setenv("KUBERNETES_SERVICE_PORT", "")
+	t.Setenv("KUBECONFIG", writeKubeconfig(t))
+
+	cfg, err := restConfig("", "")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.Host != "https://api.prod.example.com:6443" {
+		t.Errorf("expected $KUBECONFIG's current context, got %q", cfg.Host)
+	}
+}
@@ -1,10 +1,12 @@
-// Command health-checker is an in-cluster OpenShift platform health checker.
-// It periodically polls OpenShift/Kubernetes APIs and exposes five binary
-// Prometheus gauges (0=healthy, 1=unhealthy) at /metrics.
+// Command health-checker is an OpenShift platform health checker. It runs
+// in-cluster, or from outside the cluster with a kubeconfig, periodically
+// polls OpenShift/Kubernetes APIs and exposes one binary Prometheus gauge per
+// check (0=healthy, 1=unhealthy) at /metrics and the latest results at /status.
 package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"log"
 	"net/http"
@@ -13,28 +15,43 @@ import (
 	"syscall"
 
 	openshiftclient "github.com/openshift/client-go/config/clientset/versioned"
+	mcfgclient "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"k8s.io/client-go/kubernetes"
-	"k8s.io/client-go/rest"
 
 	"github.com/openshift-cluster-check/health-checker/internal/checker"
 	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/leader"
 	"github.com/openshift-cluster-check/health-checker/internal/metrics"
 )
 
 func main() {
-	// 1. Parse configuration from environment variables.
+	// `health-checker check --once` runs the checks once and exits; without a
+	// subcommand the health-checker serves metrics and checks continuously.
+	if len(os.Args) > 1 && os.Args[1] == "check" {
+		os.Exit(runCheck(os.Args[2:]))
+	}
+
+	kubeconfig := flag.String("kubeconfig", "", "Path to a kubeconfig file. Default: in-cluster config, or $KUBECONFIG / ~/.kube/config outside a pod.")
+	kubeContext := flag.String("context", "", "Kubeconfig context to use instead of the current context.")
+	flag.Parse()
+
+	// 1. Parse configuration from environment variables and the optional config file.
 	cfg, err := config.Load()
 	if err != nil {
 		log.Fatalf("ERROR: invalid configuration: %v", err)
 	}
+	reloader := config.NewReloader(cfg)
+	if cfg.ConfigFile != "" {
+		log.Printf("INFO: Using config file %s (reloaded before each check cycle)", cfg.ConfigFile)
+	}
 
 	log.Printf("INFO: Starting health-checker: interval=%s port=%d", cfg.CheckInterval, cfg.MetricsPort)
 
-	// 2. Build in-cluster Kubernetes config.
-	restCfg, err := rest.InClusterConfig()
+	// 2. Build the Kubernetes client config: kubeconfig if given, otherwise in-cluster.
+	restCfg, err := restConfig(*kubeconfig, *kubeContext)
 	if err != nil {
-		log.Fatalf("ERROR: failed to build in-cluster config: %v", err)
+		log.Fatalf("ERROR: %v", err)
 	}
 
 	// 3. Create Kubernetes client.
@@ -43,17 +60,18 @@ func main() {
 		log.Fatalf("ERROR: failed to create Kubernetes client: %v", err)
 	}
 
-	// 4. Create OpenShift config client (for ClusterOperators and ClusterVersion).
+	// 4. Create OpenShift config client (for ClusterOperators and ClusterVersion)
+	// and machineconfiguration client (for MachineConfigPools).
 	ocpClientset, err := openshiftclient.NewForConfig(restCfg)
 	if err != nil {
 		log.Fatalf("ERROR: failed to create OpenShift client: %v", err)
 	}
-	ocpClient := ocpClientset.ConfigV1()
-
-	// 5. Register Prometheus metrics.
-	metrics.Register()
+	mcfgClientset, err := mcfgclient.NewForConfig(restCfg)
+	if err != nil {
+		log.Fatalf("ERROR: failed to create OpenShift machineconfiguration client: %v", err)
+	}
 
-	// 6. Set up context with OS signal handling for graceful shutdown.
+	// 5. Set up context with OS signal handling for graceful shutdown.
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -65,16 +83,132 @@ func main() {
 		cancel()
 	}()
 
-	// 7. Run one initial check cycle so metrics are populated before the first scrape.
-	log.Println("INFO: Running initial health check cycle...")
-	checker.RunChecks(ctx, k8sClient, ocpClient, cfg)
+	// 6. Register Prometheus metrics.
+	metrics.Register()
+	metrics.ConfigHash.Set(float64(cfg.Hash()))
+
+	// 7. Build the check registry once and validate the configuration against
+	// it, so configuration errors stop the process before it takes part in
+	// leader election. Its metrics persist across leadership terms.
+	registry, informerSource, err := newRegistry(reloader, k8sClient, ocpClientset, mcfgClientset)
+	if err != nil {
+		log.Fatalf("ERROR: %v", err)
+	}
+
+	// runChecker runs an initial cycle followed by the periodic loop, until ctx
+	// is cancelled. In watch mode the informers run for as long as ctx.
+	runChecker := func(ctx context.Context, afterInitialCycle func()) error {
+		cfg := reloader.Current()
+		var trigger <-chan struct{}
+		if informerSource != nil {
+			if err := informerSource.Start(ctx); err != nil {
+				return fmt.Errorf("failed to start informers: %w", err)
+			}
+			trigger = checker.Debounce(ctx, informerSource.Changes(), cfg.WatchDebounce)
+		}
+
+		// Run one initial check cycle so metrics are populated before the first scrape.
+		log.Println("INFO: Running initial health check cycle...")
+		checker.RunChecks(ctx, registry, cfg)
+		afterInitialCycle()
 
-	// 8. Start the HTTP server for /metrics.
+		checker.StartLoop(ctx, registry, reloader, trigger)
+		return nil
+	}
+
+	var server *http.Server
+	if !cfg.LeaderElection {
+		// 8. Run the checker; the HTTP server starts after the initial cycle and
+		// the loop blocks until the context is cancelled.
+		metrics.Leader.Set(1)
+		err := runChecker(ctx, func() {
+			server = startServer(cfg, func() string { return "ok" }, registry.Latest)
+		})
+		if err != nil {
+			log.Fatalf("ERROR: %v", err)
+		}
+	} else {
+		// 8. Take part in leader election. Only the leader runs checks; followers
+		// serve /metrics and report standby until they take over. A leader that
+		// cannot run the checks gives up the Lease and exits rather than hold it
+		// without checking anything.
+		fatal := make(chan error, 1)
+		elector, err := leader.New(k8sClient, cfg, func(leaderCtx context.Context) {
+			err := runChecker(leaderCtx, func() {})
+			// The loop has stopped, so no cycle can publish after this: stop
+			// exporting results that the new leader now owns.
+			registry.Reset()
+			if err != nil && leaderCtx.Err() == nil {
+				fatal <- err
+				cancel()
+			}
+		})
+		if err != nil {
+			log.Fatalf("ERROR: %v", err)
+		}
+		server = startServer(cfg, func() string {
+			if elector.IsLeader() {
+				return "ok"
+			}
+			return "standby"
+		}, registry.Latest)
+		elector.Run(ctx)
+		select {
+		case err := <-fatal:
+			log.Fatalf("ERROR: %v", err)
+		default:
+		}
+	}
+
+	// 9. Graceful HTTP server shutdown.
+	if server != nil {
+		shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
+		defer shutdownCancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("WARNING: HTTP server shutdown error: %v", err)
+		}
+	}
+
+	log.Println("INFO: health-checker stopped.")
+}
+
+// newRegistry builds the check registry on top of the configured object
+// source: live List calls (polling) or informer caches (watch mode), and
+// checks the configuration against it. In watch mode it also returns the
+// InformerSource, which must be started before each run of the checks.
+func newRegistry(reloader *config.Reloader, k8sClient kubernetes.Interface, ocpClientset openshiftclient.Interface, mcfgClientset mcfgclient.Interface) (*checker.Registry, *checker.InformerSource, error) {
+	cfg := reloader.Current()
+	var source checker.Source = checker.NewAPISource(k8sClient, ocpClientset.ConfigV1(), mcfgClientset.MachineconfigurationV1())
+	var informerSource *checker.InformerSource
+	if cfg.WatchMode {
+		informerSource = checker.NewInformerSource(k8sClient, ocpClientset, mcfgClientset, reloader.Current)
+		source = informerSource
+		log.Printf("INFO: Watch mode enabled: debounce=%s", cfg.WatchDebounce)
+	}
+
+	registry := checker.NewDefaultRegistry(source)
+	if err := registry.Validate(cfg); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	registry.SetOperatorGroups(cfg.OperatorGroups)
+	if err := registry.SetDisabled(cfg.Disabled()); err != nil {
+		return nil, nil, fmt.Errorf("invalid DISABLED_CHECKS: %w", err)
+	}
+	return registry, informerSource, nil
+}
+
+// startServer starts the HTTP server for /metrics, /healthz and /status in the
+// background. /healthz always returns 200 with the body returned by status
+// ("ok", or "standby" for a leader election follower), so followers are not
+// restarted by the liveness probe. /status serves the cycle returned by latest
+// (see statusHandler).
+func startServer(cfg config.Config, status func() string, latest func() (checker.Snapshot, bool)) *http.Server {
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/status", statusHandler(latest))
 	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
-		fmt.Fprintln(w, "ok")
+		fmt.Fprintln(w, status())
 	})
 
 	addr := fmt.Sprintf(":%d", cfg.MetricsPort)
@@ -89,16 +223,5 @@ func main() {
 			log.Fatalf("ERROR: HTTP server failed: %v", err)
 		}
 	}()
-
-	// 9. Start the periodic checker loop (blocks until context is cancelled).
-	checker.StartLoop(ctx, k8sClient, ocpClient, cfg)
-
-	// 10. Graceful HTTP server shutdown.
-	shutdownCtx, shutdownCancel := context.WithCancel(context.Background())
-	defer shutdownCancel()
-	if err := server.Shutdown(shutdownCtx); err != nil {
-		log.Printf("WARNING: HTTP server shutdown error: %v", err)
-	}
-
-	log.Println("INFO: health-checker stopped.")
+	return server
 }
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/openshift-cluster-check/health-checker/internal/checker"
+)
+
+// statusHandler serves the outcome of the latest check cycle as JSON: the
+// overall status, when the cycle completed, and every check's result with its
+// reasons, affected objects and failing conditions. It returns 503 while no
+// cycle has completed, e.g. before the initial cycle or on a leader election
+// follower.
+func statusHandler(latest func() (checker.Snapshot, bool)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		snapshot, ok := latest()
+		if !ok {
+			http.Error(w, "no check results yet", http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(snapshot); err != nil {
+			log.Printf("WARNING: failed to write /status response: %v", err)
+		}
+	}
+}
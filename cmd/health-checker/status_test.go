@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openshift-cluster-check/health-checker/internal/checker"
+)
+
+func TestStatusHandler(t *testing.T) {
+	snapshot := checker.Snapshot{
+		Time:   time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC),
+		Status: checker.StatusUnhealthy,
+		Checks: []checker.Result{{
+			Check:    "clusteroperators",
+			Status:   checker.StatusUnhealthy,
+			Affected: []checker.ObjectRef{{Kind: "ClusterOperator", Name: "ingress"}},
+			Conditions: []checker.Condition{{
+				Object:  checker.ObjectRef{Kind: "ClusterOperator", Name: "ingress"},
+				Type:    "Degraded",
+				Status:  "True",
+				Reason:  "IngressDegraded",
+				Message: "The default ingress controller is degraded",
+			}},
+		}},
+	}
+
+	rec := httptest.NewRecorder()
+	statusHandler(func() (checker.Snapshot, bool) { return snapshot, true }).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	var got checker.Snapshot
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+		t.Fatalf("expected valid JSON, got: %v\n%s", err, rec.Body.String())
+	}
+	if got.Status != checker.StatusUnhealthy || !got.Time.Equal(snapshot.Time) || len(got.Checks) != 1 {
+		t.Fatalf("expected the snapshot back, got %+v", got)
+	}
+	if conds := got.Checks[0].Conditions; len(conds) != 1 || conds[0].Reason != "IngressDegraded" || conds[0].Message != snapshot.Checks[0].Conditions[0].Message {
+		t.Errorf("expected the failing condition in the response, got %+v", conds)
+	}
+}
+
+func TestStatusHandler_NoResults(t *testing.T) {
+	rec := httptest.NewRecorder()
+	statusHandler(func() (checker.Snapshot, bool) { return checker.Snapshot{}, false }).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/status", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503 before the first cycle, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,290 @@
+package checker
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+const (
+	// defaultKubeletPort is used when a node does not report its kubelet endpoint.
+	defaultKubeletPort = 10250
+
+	// kubeletProbeTimeout bounds a single TLS handshake with a kubelet.
+	kubeletProbeTimeout = 5 * time.Second
+
+	// kubeletProbeConcurrency is the number of kubelets probed in parallel.
+	kubeletProbeConcurrency = 10
+
+	// certRefreshSlack lets a cycle that starts slightly less than
+	// CHECK_INTERVAL after the last evaluation refresh it, so periodic cycles
+	// are never served a reused result.
+	certRefreshSlack = time.Second
+)
+
+// certificatesCheck reports whether any platform certificate expires within
+// cfg.CertExpiryThreshold (openshift_certificates_expiring).
+type certificatesCheck struct {
+	source Source
+
+	// secrets holds the openshift_tls_secret_expiry_timestamp_seconds series.
+	secrets *metrics.Series
+
+	// kubelets holds the openshift_kubelet_serving_cert_expiry_timestamp_seconds series.
+	kubelets *metrics.Series
+
+	// probe returns the expiry of a node's kubelet serving certificate.
+	probe func(ctx context.Context, node *corev1.Node) (time.Time, error)
+
+	// last is the latest healthy or unhealthy result, evaluated at lastRun,
+	// reused in watch mode until CHECK_INTERVAL has passed.
+	last    *Result
+	lastRun time.Time
+
+	now func() time.Time
+}
+
+// NewCertificatesCheck returns the "certificates" check.
+func NewCertificatesCheck(source Source) Check {
+	return &certificatesCheck{
+		source:   source,
+		secrets:  metrics.NewSeries(metrics.TLSSecretExpiry),
+		kubelets: metrics.NewSeries(metrics.KubeletCertExpiry),
+		probe:    probeKubeletCert,
+		now:      time.Now,
+	}
+}
+
+// Name implements Check.
+func (c *certificatesCheck) Name() string { return "certificates" }
+
+// reset implements resetter.
+func (c *certificatesCheck) reset() {
+	c.secrets.Reset()
+	c.kubelets.Reset()
+	c.last = nil
+}
+
+// Run parses the certificates in every kubernetes.io/tls Secret in the system
+// namespaces and, if cfg.KubeletCertCheck is set, the serving certificate of
+// every kubelet. Any certificate that expires within cfg.CertExpiryThreshold
+// makes the check unhealthy. The soonest expiry of every Secret and kubelet is
+// published as a labelled gauge.
+//
+// Secrets whose tls.crt cannot be parsed, and kubelets that cannot be reached,
+// are logged and skipped.
+// On API error, the result is unknown.
+//
+// Secrets are never cached, so every evaluation lists them from the API
+// server. In watch mode, where a cycle can run every WATCH_DEBOUNCE, the
+// Secrets and kubelets are evaluated at most once per cfg.CheckInterval;
+// cycles in between reuse the last healthy or unhealthy result.
+func (c *certificatesCheck) Run(ctx context.Context, cfg config.Config) Result {
+	now := c.now()
+	if cfg.WatchMode && c.last != nil && now.Sub(c.lastRun) < cfg.CheckInterval-certRefreshSlack {
+		return *c.last
+	}
+
+	result := c.evaluate(ctx, cfg, now)
+	if result.Status == StatusUnknown {
+		c.last = nil
+	} else {
+		c.last, c.lastRun = &result, now
+	}
+	return result
+}
+
+// evaluate runs the check against the cluster (see Run).
+func (c *certificatesCheck) evaluate(ctx context.Context, cfg config.Config, now time.Time) Result {
+	result := newResult(c.Name())
+
+	namespaces, err := c.source.ListNamespaces(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list Namespaces: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Namespaces: %w", err))
+		return result
+	}
+
+	var samples []metrics.Sample
+	for _, ns := range namespaces {
+		if !IsSystemNamespace(ns.Name, cfg) {
+			continue
+		}
+
+		secrets, err := c.source.ListTLSSecrets(ctx, ns.Name)
+		if err != nil {
+			log.Printf("WARNING: failed to list TLS Secrets in namespace %q: %v — check status unknown", ns.Name, err)
+			result.fail(fmt.Errorf("failed to list TLS Secrets in namespace %q: %w", ns.Name, err))
+			return result
+		}
+
+		for _, secret := range secrets {
+			notAfter, err := soonestExpiry(secret.Data[corev1.TLSCertKey])
+			if err != nil {
+				log.Printf("WARNING: Secret %q in namespace %q: %v — skipping", secret.Name, secret.Namespace, err)
+				continue
+			}
+			samples = append(samples, metrics.Sample{Labels: []string{secret.Namespace, secret.Name}, Value: float64(notAfter.Unix())})
+			if notAfter.Sub(now) < cfg.CertExpiryThreshold {
+				reason := fmt.Sprintf("Certificate in Secret %q in namespace %q %s", secret.Name, secret.Namespace, expiryText(notAfter, now))
+				log.Printf("WARNING: %s", reason)
+				result.addFailure(ObjectRef{Kind: "Secret", Namespace: secret.Namespace, Name: secret.Name}, reason)
+			}
+		}
+	}
+	c.secrets.Update(samples)
+
+	if !cfg.KubeletCertCheck {
+		c.kubelets.Update(nil)
+		return result
+	}
+
+	nodes, err := c.source.ListNodes(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list Nodes: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Nodes: %w", err))
+		return result
+	}
+
+	expiries := c.probeKubelets(ctx, nodes)
+	samples = make([]metrics.Sample, 0, len(expiries))
+	for _, node := range nodes {
+		notAfter, ok := expiries[node.Name]
+		if !ok {
+			continue
+		}
+		samples = append(samples, metrics.Sample{Labels: []string{node.Name}, Value: float64(notAfter.Unix())})
+		if notAfter.Sub(now) < cfg.CertExpiryThreshold {
+			reason := fmt.Sprintf("Kubelet serving certificate of Node %q %s", node.Name, expiryText(notAfter, now))
+			log.Printf("WARNING: %s", reason)
+			result.addFailure(ObjectRef{Kind: "Node", Name: node.Name}, reason)
+		}
+	}
+	c.kubelets.Update(samples)
+
+	return result
+}
+
+// probeKubelets probes the kubelet serving certificate of every node, a few at
+// a time, and returns the expiry by node name. Nodes that cannot be probed are
+// logged and left out.
+func (c *certificatesCheck) probeKubelets(ctx context.Context, nodes []*corev1.Node) map[string]time.Time {
+	var (
+		mu       sync.Mutex
+		wg       sync.WaitGroup
+		expiries = make(map[string]time.Time, len(nodes))
+		sem      = make(chan struct{}, kubeletProbeConcurrency)
+	)
+	for _, node := range nodes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			notAfter, err := c.probe(ctx, node)
+			if err != nil {
+				log.Printf("WARNING: failed to probe kubelet serving certificate of Node %q: %v — skipping", node.Name, err)
+				return
+			}
+			mu.Lock()
+			expiries[node.Name] = notAfter
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return expiries
+}
+
+// probeKubeletCert completes a TLS handshake with the node's kubelet and
+// returns the expiry of the serving certificate it presents.
+func probeKubeletCert(ctx context.Context, node *corev1.Node) (time.Time, error) {
+	host := nodeAddress(node)
+	if host == "" {
+		return time.Time{}, errors.New("node has no InternalIP or Hostname address")
+	}
+	port := int(node.Status.DaemonEndpoints.KubeletEndpoint.Port)
+	if port == 0 {
+		port = defaultKubeletPort
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, kubeletProbeTimeout)
+	defer cancel()
+
+	// Only the certificate's expiry is read and nothing is sent over the
+	// connection, so the certificate chain does not need to be verified.
+	dialer := &tls.Dialer{Config: &tls.Config{InsecureSkipVerify: true}} // #nosec G402
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer conn.Close()
+
+	certs := conn.(*tls.Conn).ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return time.Time{}, errors.New("kubelet presented no certificate")
+	}
+	return certs[0].NotAfter, nil
+}
+
+// nodeAddress returns the node's InternalIP, falling back to its Hostname.
+func nodeAddress(node *corev1.Node) string {
+	var hostname string
+	for _, addr := range node.Status.Addresses {
+		switch addr.Type {
+		case corev1.NodeInternalIP:
+			return addr.Address
+		case corev1.NodeHostName:
+			hostname = addr.Address
+		}
+	}
+	return hostname
+}
+
+// soonestExpiry returns the earliest NotAfter of the PEM-encoded certificates
+// in data (a certificate, optionally followed by its chain).
+func soonestExpiry(data []byte) (time.Time, error) {
+	var soonest time.Time
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid certificate in %s: %w", corev1.TLSCertKey, err)
+		}
+		if soonest.IsZero() || cert.NotAfter.Before(soonest) {
+			soonest = cert.NotAfter
+		}
+	}
+	if soonest.IsZero() {
+		return time.Time{}, fmt.Errorf("no certificate found in %s", corev1.TLSCertKey)
+	}
+	return soonest, nil
+}
+
+// expiryText describes when a certificate expires relative to now.
+func expiryText(notAfter, now time.Time) string {
+	if !notAfter.After(now) {
+		return fmt.Sprintf("expired at %s", notAfter.UTC().Format(time.RFC3339))
+	}
+	return fmt.Sprintf("expires at %s (in %s)", notAfter.UTC().Format(time.RFC3339), notAfter.Sub(now).Truncate(time.Minute))
+}
@@ -0,0 +1,181 @@
+package checker
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// makeCertPEM returns a PEM-encoded self-signed certificate expiring at notAfter.
+func makeCertPEM(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "test"},
+		NotBefore:    notAfter.Add(-365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func makeTLSSecret(name, namespace string, crt []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: crt},
+	}
+}
+
+func certTestConfig() config.Config {
+	return config.Config{
+		SystemNamespacePrefixes: []string{"openshift-"},
+		CertExpiryThreshold:     7 * 24 * time.Hour,
+	}
+}
+
+func TestCertificatesCheck_Secrets(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-app"}},
+		makeTLSSecret("router-certs", "openshift-ingress", makeCertPEM(t, now.Add(90*24*time.Hour))),
+		// A chain is as valid as its soonest-expiring certificate.
+		makeTLSSecret("metrics-tls", "openshift-ingress", append(makeCertPEM(t, now.Add(90*24*time.Hour)), makeCertPEM(t, now.Add(2*24*time.Hour))...)),
+		makeTLSSecret("empty", "openshift-ingress", nil),
+		makeTLSSecret("app-tls", "my-app", makeCertPEM(t, now.Add(-time.Hour))),
+	)
+
+	result := NewCertificatesCheck(NewAPISource(client, nil, nil)).Run(context.Background(), certTestConfig())
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "metrics-tls" {
+		t.Errorf("expected only metrics-tls to be affected, got %v", result.Affected)
+	}
+
+	got := testutil.ToFloat64(metrics.TLSSecretExpiry.WithLabelValues("openshift-ingress", "metrics-tls"))
+	if want := float64(now.Add(2 * 24 * time.Hour).Unix()); got != want {
+		t.Errorf("expected soonest expiry %v, got %v", want, got)
+	}
+}
+
+func TestCertificatesCheck_Kubelets(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		makeNode("worker-0", corev1.ConditionTrue, "worker"),
+		makeNode("worker-1", corev1.ConditionTrue, "worker"),
+	)
+	check := NewCertificatesCheck(NewAPISource(client, nil, nil)).(*certificatesCheck)
+	check.probe = func(_ context.Context, node *corev1.Node) (time.Time, error) {
+		if node.Name == "worker-1" {
+			return now.Add(time.Hour), nil
+		}
+		return now.Add(30 * 24 * time.Hour), nil
+	}
+
+	cfg := certTestConfig()
+	cfg.KubeletCertCheck = true
+	result := check.Run(context.Background(), cfg)
+	if result.Status != StatusUnhealthy || len(result.Affected) != 1 || result.Affected[0].Name != "worker-1" {
+		t.Errorf("expected worker-1's kubelet certificate to be reported, got %+v", result)
+	}
+}
+
+func TestCertificatesCheck_WatchModeRateLimit(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}})
+	check := NewCertificatesCheck(NewAPISource(client, nil, nil)).(*certificatesCheck)
+	check.now = func() time.Time { return now }
+	probes := 0
+	check.probe = func(context.Context, *corev1.Node) (time.Time, error) {
+		probes++
+		return now.Add(30 * 24 * time.Hour), nil
+	}
+	if _, err := client.CoreV1().Nodes().Create(context.Background(), makeNode("worker-0", corev1.ConditionTrue, "worker"), metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := certTestConfig()
+	cfg.WatchMode = true
+	cfg.KubeletCertCheck = true
+	cfg.CheckInterval = 30 * time.Second
+	if result := check.Run(context.Background(), cfg); result.Status != StatusHealthy {
+		t.Fatalf("expected healthy, got %+v", result)
+	}
+
+	expiring := makeTLSSecret("router-certs", "openshift-ingress", makeCertPEM(t, now.Add(time.Hour)))
+	if _, err := client.CoreV1().Secrets("openshift-ingress").Create(context.Background(), expiring, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// A watch-triggered cycle within CHECK_INTERVAL reuses the last result.
+	now = now.Add(10 * time.Second)
+	if result := check.Run(context.Background(), cfg); result.Status != StatusHealthy || probes != 1 {
+		t.Errorf("expected the previous healthy result without new probes, got %+v after %d probes", result, probes)
+	}
+
+	// The next periodic cycle evaluates again.
+	now = now.Add(20 * time.Second)
+	if result := check.Run(context.Background(), cfg); result.Status != StatusUnhealthy || probes != 2 {
+		t.Errorf("expected a fresh unhealthy result, got %+v after %d probes", result, probes)
+	}
+}
+
+func TestProbeKubeletCert(t *testing.T) {
+	server := httptest.NewTLSServer(http.NotFoundHandler())
+	defer server.Close()
+
+	host, portStr, err := net.SplitHostPort(server.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	port, _ := strconv.Atoi(portStr)
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0"},
+		Status: corev1.NodeStatus{
+			Addresses:       []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: host}},
+			DaemonEndpoints: corev1.NodeDaemonEndpoints{KubeletEndpoint: corev1.DaemonEndpoint{Port: int32(port)}},
+		},
+	}
+
+	notAfter, err := probeKubeletCert(context.Background(), node)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !notAfter.Equal(server.Certificate().NotAfter) {
+		t.Errorf("expected %v, got %v", server.Certificate().NotAfter, notAfter)
+	}
+}
+
+func TestSoonestExpiry_Invalid(t *testing.T) {
+	if _, err := soonestExpiry([]byte("not a certificate")); err == nil {
+		t.Error("expected error for data without a certificate, got nil")
+	}
+}
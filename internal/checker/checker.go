@@ -2,35 +2,278 @@ package checker
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"strings"
+	"sync"
 	"time"
 
-	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
-	"k8s.io/client-go/kubernetes"
-
 	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// Status is the outcome of a single check evaluation.
+type Status string
+
+const (
+	// StatusHealthy means the check found no problems.
+	StatusHealthy Status = "healthy"
+	// StatusUnhealthy means the check found at least one problem.
+	StatusUnhealthy Status = "unhealthy"
+	// StatusUnknown means the check could not evaluate the cluster (API error,
+	// timeout, panic), so the cluster's health is not known.
+	StatusUnknown Status = "unknown"
 )
 
-// RunChecks executes all five health checks independently.
-// A failure in one check does not prevent the others from running.
-// Each check updates its own Prometheus metric.
-func RunChecks(ctx context.Context, k8sClient kubernetes.Interface, ocpClient configv1client.ConfigV1Interface, cfg config.Config) {
+// ObjectRef identifies a cluster object that contributed to an unhealthy result.
+// Namespace is empty for cluster-scoped objects.
+type ObjectRef struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+}
+
+// Condition is a failing status condition of an affected object, with the
+// reason and message set by the object's own controller.
+type Condition struct {
+	Object  ObjectRef `json:"object"`
+	Type    string    `json:"type"`
+	Status  string    `json:"status"`
+	Reason  string    `json:"reason,omitempty"`
+	Message string    `json:"message,omitempty"`
+}
+
+// Result is the structured outcome of a check. It is returned by Check.Run and
+// consumed by the registry (to update the check's binary gauge) and by any
+// other output that wants to report on cluster health.
+type Result struct {
+	// Check is the name of the check that produced this result.
+	Check string `json:"check"`
+
+	// Status is the overall outcome of the check.
+	Status Status `json:"status"`
+
+	// Reasons are human-readable explanations for an unhealthy or unknown status.
+	Reasons []string `json:"reasons,omitempty"`
+
+	// Affected lists the objects that caused an unhealthy status.
+	Affected []ObjectRef `json:"affected,omitempty"`
+
+	// Conditions lists the failing conditions of the affected objects, for
+	// checks that evaluate status conditions (e.g. ClusterOperators).
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	// Err is set when the check could not evaluate the cluster (API error,
+	// timeout, panic). A result with Err set always has StatusUnknown.
+	Err error `json:"-"`
+}
+
+// Healthy reports whether the result has StatusHealthy.
+func (r Result) Healthy() bool {
+	return r.Status == StatusHealthy
+}
+
+// OverallStatus combines the results of a cycle into a single status:
+// unhealthy if any check is unhealthy, otherwise unknown if any check is
+// unknown, otherwise healthy. A definite problem outranks an unknown.
+func OverallStatus(results []Result) Status {
+	status := StatusHealthy
+	for _, r := range results {
+		switch r.Status {
+		case StatusUnhealthy:
+			return StatusUnhealthy
+		case StatusUnknown:
+			status = StatusUnknown
+		}
+	}
+	return status
+}
+
+// newResult returns a healthy result for the named check.
+func newResult(check string) Result {
+	return Result{Check: check, Status: StatusHealthy}
+}
+
+// addFailure marks the result unhealthy and records the affected object and reason.
+func (r *Result) addFailure(ref ObjectRef, reason string) {
+	r.Status = StatusUnhealthy
+	r.Affected = append(r.Affected, ref)
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// addConditions records the failing conditions of an affected object.
+func (r *Result) addConditions(conditions ...Condition) {
+	r.Conditions = append(r.Conditions, conditions...)
+}
+
+// fail marks the result unknown because the check could not evaluate the
+// cluster, recording err as both the result's error and a reason.
+// Objects already recorded as affected are kept for context.
+func (r *Result) fail(err error) {
+	r.Status = StatusUnknown
+	r.Err = err
+	r.Reasons = append(r.Reasons, err.Error())
+}
+
+// maxLabelMessageLength is the maximum length, in runes, of a free-text
+// message used as a metric label value.
+const maxLabelMessageLength = 256
+
+// labelMessage makes a free-text message, such as a condition message, fit
+// for a metric label value: whitespace, including newlines, is collapsed to
+// single spaces and the message is cut to maxLabelMessageLength runes.
+func labelMessage(message string) string {
+	message = strings.Join(strings.Fields(message), " ")
+	if runes := []rune(message); len(runes) > maxLabelMessageLength {
+		message = string(runes[:maxLabelMessageLength-1]) + "…"
+	}
+	return message
+}
+
+// boolToFloat converts a boolean to a gauge value: 1 for true, 0 for false.
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// Check is a single, independently runnable health check.
+//
+// Implementations must be safe to call repeatedly; each call to Run evaluates
+// the cluster afresh and returns a complete Result. Errors talking to the API
+// are reported through the Result as StatusUnknown, never as a Go error.
+type Check interface {
+	// Name returns the unique, stable identifier of the check (e.g. "nodes").
+	// It is used for enabling/disabling the check via configuration.
+	Name() string
+
+	// Run evaluates the check once.
+	Run(ctx context.Context, cfg config.Config) Result
+}
+
+// RunChecks executes every enabled check in the registry concurrently, each
+// with its own deadline (cfg.TimeoutFor). A failure or timeout in one check
+// does not prevent or delay the others. Each result is published to the
+// check's gauge (see entry.publish) and returned to the caller in registration order.
+// The results are also kept as the registry's latest Snapshot.
+//
+// If a previous cycle on the same registry is still running, RunChecks logs a
+// warning and returns nil without starting a new one, so cycles never overlap.
+func RunChecks(ctx context.Context, reg *Registry, cfg config.Config) []Result {
+	if !reg.cycle.TryLock() {
+		log.Println("WARNING: Previous health check cycle still running — skipping this cycle.")
+		return nil
+	}
+	defer reg.cycle.Unlock()
+
 	log.Println("INFO: Running health checks...")
 
-	// Each check is called independently; errors are handled internally per check.
-	CheckClusterOperators(ctx, ocpClient)
-	CheckClusterVersion(ctx, ocpClient)
-	CheckNodes(ctx, k8sClient)
-	CheckSystemPods(ctx, k8sClient, cfg)
+	entries := reg.enabled()
+	results := make([]Result, len(entries))
+
+	var wg sync.WaitGroup
+	for i, e := range entries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			results[i] = runWithTimeout(ctx, e, cfg)
+			observe(results[i], time.Since(start))
+			e.publish(results[i], cfg)
+		}()
+	}
+	wg.Wait()
+	metrics.CyclesTotal.Inc()
+	reg.latest.Store(&Snapshot{Time: time.Now(), Status: OverallStatus(results), Checks: results})
 
 	log.Println("INFO: Health checks complete.")
+	return results
+}
+
+// observe records the self-observability metrics for a single check run.
+func observe(result Result, duration time.Duration) {
+	metrics.CheckDuration.WithLabelValues(result.Check).Observe(duration.Seconds())
+	if result.Err != nil {
+		metrics.CheckErrors.WithLabelValues(result.Check, errorReason(result.Err)).Inc()
+		return
+	}
+	metrics.CheckLastSuccess.WithLabelValues(result.Check).SetToCurrentTime()
+}
+
+// runWithTimeout runs a single check with the deadline configured for it.
+// A non-positive timeout means no deadline. A check that times out or panics
+// is reported as unknown.
+//
+// The check keeps running in the background after a timeout until it observes
+// the cancelled context; until it returns, later cycles report it as still in
+// progress instead of starting a second copy.
+func runWithTimeout(ctx context.Context, e *entry, cfg config.Config) Result {
+	name := e.check.Name()
+
+	if !e.inflight.CompareAndSwap(false, true) {
+		log.Printf("WARNING: Check %q is still running from a previous cycle — check status unknown", name)
+		result := newResult(name)
+		result.fail(errStillRunning)
+		return result
+	}
+
+	timeout := cfg.TimeoutFor(name)
+	var checkCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		checkCtx, cancel = context.WithTimeout(ctx, timeout)
+	} else {
+		checkCtx, cancel = context.WithCancel(ctx)
+	}
+	defer cancel()
+
+	done := make(chan Result, 1)
+	go func() {
+		defer e.inflight.Store(false)
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("ERROR: Check %q panicked: %v — check status unknown", name, r)
+				result := newResult(name)
+				result.fail(fmt.Errorf("%w: %v", errPanicked, r))
+				done <- result
+			}
+		}()
+		done <- e.check.Run(checkCtx, cfg)
+	}()
+
+	select {
+	case result := <-done:
+		return result
+	case <-checkCtx.Done():
+		// Prefer a result that raced with the deadline over a synthetic one.
+		select {
+		case result := <-done:
+			return result
+		default:
+		}
+		if ctx.Err() != nil {
+			result := newResult(name)
+			result.fail(errCancelled)
+			return result
+		}
+		log.Printf("WARNING: Check %q did not finish within %s — check status unknown", name, timeout)
+		result := newResult(name)
+		result.fail(fmt.Errorf("%w after %s", errTimedOut, timeout))
+		return result
+	}
 }
 
-// StartLoop runs RunChecks on the configured interval using a ticker.
+// StartLoop runs RunChecks on the configured interval using a ticker, and
+// additionally whenever a value is received on trigger (watch mode). A nil
+// trigger never fires, giving plain polling.
+// Before every cycle the configuration is reloaded from reloader, so config
+// file changes are applied atomically at the start of the next cycle.
 // It blocks until the context is cancelled.
 // An initial check is NOT run here — callers should call RunChecks once before
 // starting the HTTP server, then call StartLoop for subsequent periodic checks.
-func StartLoop(ctx context.Context, k8sClient kubernetes.Interface, ocpClient configv1client.ConfigV1Interface, cfg config.Config) {
+func StartLoop(ctx context.Context, reg *Registry, reloader *config.Reloader, trigger <-chan struct{}) {
+	cfg := reloader.Current()
 	ticker := time.NewTicker(cfg.CheckInterval)
 	defer ticker.Stop()
 
@@ -40,7 +283,39 @@ func StartLoop(ctx context.Context, k8sClient kubernetes.Interface, ocpClient co
 			log.Println("INFO: Checker loop stopping: context cancelled.")
 			return
 		case <-ticker.C:
-			RunChecks(ctx, k8sClient, ocpClient, cfg)
+			cfg = reloadConfig(reg, reloader, cfg, ticker)
+			RunChecks(ctx, reg, cfg)
+		case <-trigger:
+			log.Println("INFO: Watched objects changed, re-running health checks.")
+			cfg = reloadConfig(reg, reloader, cfg, ticker)
+			RunChecks(ctx, reg, cfg)
+			// The cycle just ran; postpone the next periodic one by a full interval.
+			ticker.Reset(cfg.CheckInterval)
 		}
 	}
 }
+
+// reloadConfig returns the configuration for the next cycle. If the config
+// file changed and is valid, the new configuration is applied to the registry
+// and ticker; otherwise the current configuration stays in effect.
+func reloadConfig(reg *Registry, reloader *config.Reloader, cfg config.Config, ticker *time.Ticker) config.Config {
+	next, changed, err := reloader.Reload(reg.Validate)
+	if err != nil {
+		log.Printf("ERROR: Config reload failed, keeping the current configuration: %v", err)
+		metrics.ConfigReloadFailures.Inc()
+		return cfg
+	}
+	if !changed {
+		return cfg
+	}
+
+	reg.SetOperatorGroups(next.OperatorGroups)
+	// Validate has already checked every name, so SetDisabled cannot fail.
+	_ = reg.SetDisabled(next.Disabled())
+	if next.CheckInterval != cfg.CheckInterval {
+		ticker.Reset(next.CheckInterval)
+	}
+	metrics.ConfigHash.Set(float64(next.Hash()))
+	log.Printf("INFO: Config file reloaded: interval=%s hash=%012x", next.CheckInterval, next.Hash())
+	return next
+}
@@ -0,0 +1,250 @@
+package checker
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// blockingCheck is a Check that blocks until its context is cancelled or
+// release is closed. Like the real checks, it reports a cancelled context as
+// an error.
+type blockingCheck struct {
+	name     string
+	release  chan struct{}
+	ignore   bool // ignore context cancellation
+	started  atomic.Int32
+	finished atomic.Int32
+}
+
+func (c *blockingCheck) Name() string { return c.name }
+
+func (c *blockingCheck) Run(ctx context.Context, _ config.Config) Result {
+	c.started.Add(1)
+	defer c.finished.Add(1)
+	if c.ignore {
+		<-c.release
+	} else {
+		select {
+		case <-ctx.Done():
+			result := newResult(c.name)
+			result.fail(ctx.Err())
+			return result
+		case <-c.release:
+		}
+	}
+	return newResult(c.name)
+}
+
+// panicCheck is a Check that always panics.
+type panicCheck struct{}
+
+func (panicCheck) Name() string { return "panic" }
+
+func (panicCheck) Run(context.Context, config.Config) Result { panic("boom") }
+
+// childCountingContext is a never-cancelled parent context that counts the
+// child contexts registered on it and not yet cancelled. The context package
+// registers children through AfterFunc on parents that provide it.
+type childCountingContext struct {
+	done     chan struct{}
+	children atomic.Int64
+}
+
+func (c *childCountingContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (c *childCountingContext) Done() <-chan struct{}       { return c.done }
+func (c *childCountingContext) Err() error                  { return nil }
+func (c *childCountingContext) Value(any) any               { return nil }
+
+func (c *childCountingContext) AfterFunc(func()) func() bool {
+	c.children.Add(1)
+	var stopped atomic.Bool
+	return func() bool {
+		if stopped.CompareAndSwap(false, true) {
+			c.children.Add(-1)
+			return true
+		}
+		return false
+	}
+}
+
+func testConfig(timeout time.Duration) config.Config {
+	return config.Config{CheckInterval: time.Minute, CheckTimeout: timeout}
+}
+
+func TestRunChecks_TimeoutIsUnknown(t *testing.T) {
+	reg := NewRegistry()
+	slow := &blockingCheck{name: "slow", release: make(chan struct{})}
+	fast := &staticCheck{name: "fast", status: StatusHealthy}
+	reg.MustRegister(slow, nil)
+	reg.MustRegister(fast, nil)
+
+	start := time.Now()
+	results := RunChecks(context.Background(), reg, testConfig(50*time.Millisecond))
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("expected cycle to be bounded by the check timeout, took %s", elapsed)
+	}
+
+	if results[0].Check != "slow" || results[0].Status != StatusUnknown {
+		t.Errorf("expected timed-out check to be unknown, got %+v", results[0])
+	}
+	if results[1].Check != "fast" || !results[1].Healthy() {
+		t.Errorf("expected fast check to be healthy, got %+v", results[1])
+	}
+}
+
+func TestRunChecks_PerCheckTimeout(t *testing.T) {
+	reg := NewRegistry()
+	slow := &blockingCheck{name: "slow", release: make(chan struct{})}
+	reg.MustRegister(slow, nil)
+
+	cfg := testConfig(10 * time.Millisecond)
+	cfg.CheckTimeouts = map[string]time.Duration{"slow": time.Minute}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		close(slow.release)
+	}()
+
+	results := RunChecks(context.Background(), reg, cfg)
+	if !results[0].Healthy() {
+		t.Errorf("expected check to finish within its per-check timeout, got %+v", results[0])
+	}
+}
+
+func TestRunChecks_RunsConcurrently(t *testing.T) {
+	reg := NewRegistry()
+	release := make(chan struct{})
+	a := &blockingCheck{name: "a", release: release}
+	b := &blockingCheck{name: "b", release: release}
+	reg.MustRegister(a, nil)
+	reg.MustRegister(b, nil)
+
+	done := make(chan []Result)
+	go func() { done <- RunChecks(context.Background(), reg, testConfig(time.Minute)) }()
+
+	deadline := time.After(2 * time.Second)
+	for a.started.Load() == 0 || b.started.Load() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("expected both checks to be running at the same time")
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	close(release)
+	<-done
+}
+
+func TestRunChecks_NoOverlappingCycles(t *testing.T) {
+	reg := NewRegistry()
+	slow := &blockingCheck{name: "slow", release: make(chan struct{})}
+	reg.MustRegister(slow, nil)
+
+	done := make(chan []Result)
+	go func() { done <- RunChecks(context.Background(), reg, testConfig(time.Minute)) }()
+	for slow.started.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	if results := RunChecks(context.Background(), reg, testConfig(time.Minute)); results != nil {
+		t.Errorf("expected overlapping cycle to be skipped, got %v", results)
+	}
+
+	close(slow.release)
+	<-done
+	if got := slow.started.Load(); got != 1 {
+		t.Errorf("expected check to run once, ran %d times", got)
+	}
+}
+
+func TestRunChecks_StuckCheckNotRestarted(t *testing.T) {
+	reg := NewRegistry()
+	stuck := &blockingCheck{name: "stuck", release: make(chan struct{}), ignore: true}
+	reg.MustRegister(stuck, nil)
+	defer close(stuck.release)
+
+	RunChecks(context.Background(), reg, testConfig(10*time.Millisecond))
+	results := RunChecks(context.Background(), reg, testConfig(10*time.Millisecond))
+
+	if results[0].Status != StatusUnknown {
+		t.Error("expected check still running from a previous cycle to be unknown")
+	}
+	if got := stuck.started.Load(); got != 1 {
+		t.Errorf("expected stuck check not to be started again, started %d times", got)
+	}
+}
+
+func TestRunChecks_PanicIsUnknown(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(panicCheck{}, nil)
+
+	results := RunChecks(context.Background(), reg, testConfig(time.Minute))
+	if results[0].Status != StatusUnknown {
+		t.Error("expected panicking check to be unknown")
+	}
+}
+
+func TestRunChecks_ReleasesCheckContexts(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(&staticCheck{name: "a", status: StatusHealthy}, nil)
+	reg.MustRegister(&staticCheck{name: "b", status: StatusHealthy}, nil)
+
+	for _, timeout := range []time.Duration{time.Minute, 0} {
+		ctx := &childCountingContext{done: make(chan struct{})}
+		for range 10 {
+			RunChecks(ctx, reg, testConfig(timeout))
+		}
+		if got := ctx.children.Load(); got != 0 {
+			t.Errorf("timeout %s: expected no check context left on the parent after the cycles, got %d", timeout, got)
+		}
+	}
+}
+
+func TestRunChecks_SelfObservabilityMetrics(t *testing.T) {
+	reg := NewRegistry()
+	slow := &blockingCheck{name: "selfobs-slow", release: make(chan struct{})}
+	reg.MustRegister(slow, nil)
+	reg.MustRegister(&staticCheck{name: "selfobs-ok", status: StatusUnhealthy}, nil)
+
+	cycles := testutil.ToFloat64(metrics.CyclesTotal)
+	timeouts := testutil.ToFloat64(metrics.CheckErrors.WithLabelValues("selfobs-slow", "timeout"))
+	metrics.CheckLastSuccess.DeleteLabelValues("selfobs-slow")
+	RunChecks(context.Background(), reg, testConfig(10*time.Millisecond))
+
+	if got := testutil.ToFloat64(metrics.CyclesTotal); got != cycles+1 {
+		t.Errorf("expected cycles_total to increase by 1, got %v -> %v", cycles, got)
+	}
+	if got := testutil.ToFloat64(metrics.CheckErrors.WithLabelValues("selfobs-slow", "timeout")); got != timeouts+1 {
+		t.Errorf("expected timeout errors for selfobs-slow to increase by 1, got %v -> %v", timeouts, got)
+	}
+	// An unhealthy result without an error is still a successful run of the checker.
+	if got := testutil.ToFloat64(metrics.CheckLastSuccess.WithLabelValues("selfobs-ok")); got == 0 {
+		t.Error("expected last success timestamp to be set for selfobs-ok")
+	}
+	if got := testutil.ToFloat64(metrics.CheckLastSuccess.WithLabelValues("selfobs-slow")); got != 0 {
+		t.Errorf("expected no last success timestamp for selfobs-slow, got %v", got)
+	}
+}
+
+func TestOverallStatus(t *testing.T) {
+	healthy := Result{Status: StatusHealthy}
+	unhealthy := Result{Status: StatusUnhealthy}
+	unknown := Result{Status: StatusUnknown}
+
+	if got := OverallStatus([]Result{healthy, healthy}); got != StatusHealthy {
+		t.Errorf("expected healthy, got %s", got)
+	}
+	if got := OverallStatus([]Result{healthy, unknown}); got != StatusUnknown {
+		t.Errorf("expected unknown, got %s", got)
+	}
+	if got := OverallStatus([]Result{unknown, unhealthy, healthy}); got != StatusUnhealthy {
+		t.Errorf("expected unhealthy to outrank unknown, got %s", got)
+	}
+}
@@ -4,74 +4,259 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strings"
+	"time"
 
 	configv1 "github.com/openshift/api/config/v1"
-	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	"github.com/openshift-cluster-check/health-checker/internal/config"
 	"github.com/openshift-cluster-check/health-checker/internal/metrics"
 )
 
-// CheckClusterOperators lists all ClusterOperators and updates:
-//   - openshift_cluster_operators_degraded: 1 if any operator (excluding etcd) is degraded or unavailable
-//   - openshift_etcd_degraded: 1 if the etcd operator is degraded or unavailable
-//
-// On API error, both metrics are set to 1 (fail-closed).
-func CheckClusterOperators(ctx context.Context, client configv1client.ConfigV1Interface) {
-	operators, err := client.ClusterOperators().List(ctx, metav1.ListOptions{})
-	if err != nil {
-		log.Printf("WARNING: failed to list ClusterOperators: %v — setting both metrics to 1 (fail-closed)", err)
-		metrics.ClusterOperatorsDegraded.Set(1)
-		metrics.EtcdDegraded.Set(1)
+// etcdGroup is the built-in operator group reported by the etcd check
+// (openshift_etcd_degraded). Every ClusterOperator outside it belongs to the
+// built-in group reported by the clusteroperators check
+// (openshift_cluster_operators_degraded).
+var etcdGroup = config.OperatorGroup{Name: "etcd", Operators: []string{"etcd"}}
+
+// operatorGroupCheckPrefix prefixes the group name in the name of the check
+// that reports a user-defined operator group, e.g. "operatorgroup/ingress".
+const operatorGroupCheckPrefix = "operatorgroup/"
+
+// operatorGroupCheckName returns the name of the check that reports the
+// user-defined operator group.
+func operatorGroupCheckName(group string) string {
+	return operatorGroupCheckPrefix + group
+}
+
+// operatorConditionTypes are the ClusterOperator conditions published to
+// openshift_cluster_operator_condition.
+var operatorConditionTypes = []configv1.ClusterStatusConditionType{
+	configv1.OperatorDegraded,
+	configv1.OperatorAvailable,
+	configv1.OperatorProgressing,
+	configv1.OperatorUpgradeable,
+}
+
+// clusterOperatorsCheck reports whether any ClusterOperator of an operator
+// group has Degraded=True or Available=False. The built-in groups (etcd and
+// every other operator) and the user-defined groups in cfg.OperatorGroups are
+// all reported by a clusterOperatorsCheck, each driving its own gauge.
+type clusterOperatorsCheck struct {
+	name   string
+	source Source
+
+	// group is the name of the user-defined group, or empty for a built-in
+	// group.
+	group string
+
+	// includes reports whether the named ClusterOperator belongs to the group.
+	includes func(cfg config.Config, operator string) bool
+
+	// conditions holds this check's openshift_cluster_operator_condition series.
+	// It, conditionAges and conditionInfo are nil for user-defined groups: only
+	// the built-in groups, which do not overlap, publish per-operator series.
+	conditions *metrics.Series
+
+	// conditionAges holds this check's openshift_cluster_operator_condition_seconds series.
+	conditionAges *metrics.Series
+
+	// conditionInfo holds this check's openshift_cluster_operator_condition_info series.
+	conditionInfo *metrics.Series
+
+	now func() time.Time
+}
+
+// NewClusterOperatorsCheck returns the "clusteroperators" check, which covers
+// every ClusterOperator outside etcdGroup (openshift_cluster_operators_degraded).
+func NewClusterOperatorsCheck(source Source) Check {
+	return newBuiltinOperatorGroupCheck("clusteroperators", source, func(name string) bool { return !etcdGroup.Includes(name) })
+}
+
+// NewEtcdCheck returns the "etcd" check, which covers the ClusterOperators of
+// etcdGroup (openshift_etcd_degraded).
+func NewEtcdCheck(source Source) Check {
+	return newBuiltinOperatorGroupCheck("etcd", source, etcdGroup.Includes)
+}
+
+// newBuiltinOperatorGroupCheck returns the check of a built-in operator group,
+// which also publishes the conditions of its operators.
+func newBuiltinOperatorGroupCheck(name string, source Source, includes func(name string) bool) *clusterOperatorsCheck {
+	return &clusterOperatorsCheck{
+		name:          name,
+		source:        source,
+		includes:      func(_ config.Config, operator string) bool { return includes(operator) },
+		conditions:    metrics.NewSeries(metrics.ClusterOperatorCondition),
+		conditionAges: metrics.NewSeries(metrics.ClusterOperatorConditionSeconds),
+		conditionInfo: metrics.NewSeries(metrics.ClusterOperatorConditionInfo),
+		now:           time.Now,
+	}
+}
+
+// NewOperatorGroupCheck returns the check of the user-defined operator group
+// with the given name, named operatorGroupCheckName(group). Its operators are
+// looked up in cfg.OperatorGroups on every run, so a reload that changes them
+// applies to the next cycle; operators that do not exist are ignored.
+func NewOperatorGroupCheck(source Source, group string) Check {
+	return &clusterOperatorsCheck{
+		name:   operatorGroupCheckName(group),
+		source: source,
+		group:  group,
+		includes: func(cfg config.Config, operator string) bool {
+			for _, g := range cfg.OperatorGroups {
+				if g.Name == group {
+					return g.Includes(operator)
+				}
+			}
+			return false
+		},
+		now: time.Now,
+	}
+}
+
+// Name implements Check.
+func (c *clusterOperatorsCheck) Name() string { return c.name }
+
+// reset implements resetter.
+func (c *clusterOperatorsCheck) reset() {
+	if c.conditions == nil {
 		return
 	}
+	c.conditions.Reset()
+	c.conditionAges.Reset()
+	c.conditionInfo.Reset()
+}
 
-	operatorsDegraded := false
-	etcdDegraded := false
+// Run lists all ClusterOperators and reports the ones of the group that have
+// been degraded or unavailable for at least cfg.OperatorConditionMinAgeFor,
+// measured from the condition's lastTransitionTime. The reason and message of
+// every Degraded=True or Available=False condition are recorded in the result
+// for reported operators.
+// For a built-in group, the Degraded, Available, Progressing and Upgradeable
+// conditions of every operator of the group are published to
+// openshift_cluster_operator_condition, how long they have had their current
+// status to openshift_cluster_operator_condition_seconds, and the reason and
+// message of every failing condition to
+// openshift_cluster_operator_condition_info.
+//
+// On API error, the result is unknown.
+func (c *clusterOperatorsCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.name)
 
-	for _, op := range operators.Items {
-		degraded := isOperatorDegraded(op)
-		if op.Name == "etcd" {
-			if degraded {
-				etcdDegraded = true
-				log.Printf("WARNING: ClusterOperator %q is degraded or unavailable", op.Name)
+	operators, err := c.source.ListClusterOperators(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list ClusterOperators: %v — check %q status unknown", err, c.name)
+		result.fail(fmt.Errorf("failed to list ClusterOperators: %w", err))
+		return result
+	}
+
+	now := c.now()
+	var samples, ageSamples, infoSamples []metrics.Sample
+	for _, op := range operators {
+		if !c.includes(cfg, op.Name) {
+			continue
+		}
+		if c.conditions != nil {
+			for _, condType := range operatorConditionTypes {
+				status := clusterOperatorConditionStatus(*op, condType)
+				if status == "" {
+					continue
+				}
+				samples = append(samples, metrics.Sample{
+					Labels: []string{op.Name, string(condType)},
+					Value:  boolToFloat(status == configv1.ConditionTrue),
+				})
+				if since := clusterOperatorConditionSince(*op, condType); !since.IsZero() {
+					ageSamples = append(ageSamples, metrics.Sample{
+						Labels: []string{op.Name, string(condType)},
+						Value:  now.Sub(since).Seconds(),
+					})
+				}
 			}
-		} else {
-			if degraded {
-				operatorsDegraded = true
-				log.Printf("WARNING: ClusterOperator %q is degraded or unavailable", op.Name)
+
+			for _, cond := range operatorFailingConditions(*op, 0, now) {
+				infoSamples = append(infoSamples, metrics.Sample{
+					Labels: []string{op.Name, cond.Type, cond.Status, cond.Reason, labelMessage(cond.Message)},
+					Value:  1,
+				})
 			}
 		}
-	}
 
-	if operatorsDegraded {
-		metrics.ClusterOperatorsDegraded.Set(1)
-	} else {
-		metrics.ClusterOperatorsDegraded.Set(0)
+		minAge := cfg.OperatorConditionMinAgeFor(op.Name)
+		subject := fmt.Sprintf("ClusterOperator %q", op.Name)
+		if c.group != "" {
+			subject = fmt.Sprintf("ClusterOperator %q of operator group %q", op.Name, c.group)
+		}
+		if failing := operatorFailingConditions(*op, minAge, now); len(failing) > 0 {
+			message := fmt.Sprintf("%s is degraded or unavailable: %s", subject, describeConditions(failing))
+			log.Printf("WARNING: %s", message)
+			result.addFailure(ObjectRef{Kind: "ClusterOperator", Name: op.Name}, message)
+			result.addConditions(failing...)
+		} else if isOperatorDegraded(*op, 0, now) {
+			log.Printf("INFO: %s is degraded or unavailable for less than %s — not reported yet", subject, minAge)
+		}
 	}
+	if c.conditions != nil {
+		c.conditions.Update(samples)
+		c.conditionAges.Update(ageSamples)
+		c.conditionInfo.Update(infoSamples)
+	}
+
+	return result
+}
+
+// isOperatorDegraded returns true if the ClusterOperator has had Degraded=True
+// or Available=False for at least minAge before now. A condition without a
+// lastTransitionTime counts immediately.
+func isOperatorDegraded(op configv1.ClusterOperator, minAge time.Duration, now time.Time) bool {
+	return len(operatorFailingConditions(op, minAge, now)) > 0
+}
 
-	if etcdDegraded {
-		metrics.EtcdDegraded.Set(1)
-	} else {
-		metrics.EtcdDegraded.Set(0)
+// operatorFailingConditions returns the Degraded=True and Available=False
+// conditions of the ClusterOperator that have had their status for at least
+// minAge before now, with their reason and message.
+func operatorFailingConditions(op configv1.ClusterOperator, minAge time.Duration, now time.Time) []Condition {
+	var failing []Condition
+	for _, bad := range []struct {
+		condType configv1.ClusterStatusConditionType
+		status   configv1.ConditionStatus
+	}{
+		{configv1.OperatorDegraded, configv1.ConditionTrue},
+		{configv1.OperatorAvailable, configv1.ConditionFalse},
+	} {
+		if clusterOperatorConditionStatus(op, bad.condType) != bad.status {
+			continue
+		}
+		if since := clusterOperatorConditionSince(op, bad.condType); !since.IsZero() && now.Sub(since) < minAge {
+			continue
+		}
+		failing = append(failing, Condition{
+			Object:  ObjectRef{Kind: "ClusterOperator", Name: op.Name},
+			Type:    string(bad.condType),
+			Status:  string(bad.status),
+			Reason:  clusterOperatorConditionReason(op, bad.condType),
+			Message: clusterOperatorConditionMessage(op, bad.condType),
+		})
 	}
+	return failing
 }
 
-// isOperatorDegraded returns true if the ClusterOperator has Degraded=True or Available=False.
-func isOperatorDegraded(op configv1.ClusterOperator) bool {
-	for _, cond := range op.Status.Conditions {
-		switch cond.Type {
-		case configv1.OperatorDegraded:
-			if cond.Status == configv1.ConditionTrue {
-				return true
-			}
-		case configv1.OperatorAvailable:
-			if cond.Status == configv1.ConditionFalse {
-				return true
-			}
+// describeConditions summarizes conditions for a log line or result reason,
+// e.g. "Degraded=True (RouteHealthDegraded): route not reachable". Messages are
+// shortened like label values.
+func describeConditions(conditions []Condition) string {
+	parts := make([]string, 0, len(conditions))
+	for _, cond := range conditions {
+		part := cond.Type + "=" + cond.Status
+		if cond.Reason != "" {
+			part += " (" + cond.Reason + ")"
 		}
+		if message := labelMessage(cond.Message); message != "" {
+			part += ": " + message
+		}
+		parts = append(parts, part)
 	}
-	return false
+	return strings.Join(parts, "; ")
 }
 
 // clusterOperatorConditionStatus returns the status of a named condition, or empty string if not found.
@@ -84,6 +269,27 @@ func clusterOperatorConditionStatus(op configv1.ClusterOperator, condType config
 	return ""
 }
 
+// clusterOperatorConditionSince returns the lastTransitionTime of a named
+// condition, or the zero time if not found or not set.
+func clusterOperatorConditionSince(op configv1.ClusterOperator, condType configv1.ClusterStatusConditionType) time.Time {
+	for _, cond := range op.Status.Conditions {
+		if cond.Type == condType {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// clusterOperatorConditionReason returns the reason of a named condition, or empty string if not found.
+func clusterOperatorConditionReason(op configv1.ClusterOperator, condType configv1.ClusterStatusConditionType) string {
+	for _, cond := range op.Status.Conditions {
+		if cond.Type == condType {
+			return cond.Reason
+		}
+	}
+	return ""
+}
+
 // clusterOperatorConditionMessage returns the message of a named condition, or empty string if not found.
 func clusterOperatorConditionMessage(op configv1.ClusterOperator, condType configv1.ClusterStatusConditionType) string {
 	for _, cond := range op.Status.Conditions {
@@ -93,8 +299,3 @@ func clusterOperatorConditionMessage(op configv1.ClusterOperator, condType confi
 	}
 	return ""
 }
-
-// Ensure these helpers are used (suppress unused warnings if needed).
-var _ = fmt.Sprintf
-var _ = clusterOperatorConditionStatus
-var _ = clusterOperatorConditionMessage
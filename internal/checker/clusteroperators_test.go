@@ -0,0 +1,239 @@
+package checker
+
+import (
+	"context"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	ocpfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+func makeOperator(name string, degraded, available configv1.ConditionStatus) *configv1.ClusterOperator {
+	return &configv1.ClusterOperator{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: configv1.ClusterOperatorStatus{
+			Conditions: []configv1.ClusterOperatorStatusCondition{
+				{Type: configv1.OperatorDegraded, Status: degraded},
+				{Type: configv1.OperatorAvailable, Status: available},
+			},
+		},
+	}
+}
+
+func TestClusterOperatorsCheck_ExcludesEtcd(t *testing.T) {
+	client := ocpfake.NewSimpleClientset(
+		makeOperator("etcd", configv1.ConditionTrue, configv1.ConditionTrue),
+		makeOperator("ingress", configv1.ConditionFalse, configv1.ConditionTrue),
+	)
+
+	result := NewClusterOperatorsCheck(NewAPISource(nil, client.ConfigV1(), nil)).Run(context.Background(), config.Config{})
+	if !result.Healthy() {
+		t.Errorf("expected clusteroperators check to ignore degraded etcd, got %+v", result)
+	}
+
+	result = NewEtcdCheck(NewAPISource(nil, client.ConfigV1(), nil)).Run(context.Background(), config.Config{})
+	if result.Healthy() {
+		t.Fatal("expected etcd check to be unhealthy")
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "etcd" {
+		t.Errorf("expected etcd to be the only affected object, got %v", result.Affected)
+	}
+}
+
+func TestClusterOperatorsCheck_Unavailable(t *testing.T) {
+	client := ocpfake.NewSimpleClientset(
+		makeOperator("ingress", configv1.ConditionFalse, configv1.ConditionFalse),
+	)
+
+	result := NewClusterOperatorsCheck(NewAPISource(nil, client.ConfigV1(), nil)).Run(context.Background(), config.Config{})
+	if result.Healthy() {
+		t.Fatal("expected unavailable operator to make the check unhealthy")
+	}
+	if result.Affected[0].Kind != "ClusterOperator" || result.Affected[0].Name != "ingress" {
+		t.Errorf("expected ClusterOperator ingress to be affected, got %v", result.Affected[0])
+	}
+}
+
+func TestClusterOperatorsCheck_MinAge(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	since := func(d time.Duration) metav1.Time { return metav1.NewTime(now.Add(-d)) }
+
+	ingress := makeOperator("ingress", configv1.ConditionTrue, configv1.ConditionTrue)
+	ingress.Status.Conditions[0].LastTransitionTime = since(2 * time.Minute)
+	dns := makeOperator("dns", configv1.ConditionFalse, configv1.ConditionFalse)
+	dns.Status.Conditions[1].LastTransitionTime = since(10 * time.Minute)
+	console := makeOperator("console", configv1.ConditionTrue, configv1.ConditionTrue)
+	console.Status.Conditions[0].LastTransitionTime = since(2 * time.Minute)
+	client := ocpfake.NewSimpleClientset(ingress, dns, console)
+
+	check := NewClusterOperatorsCheck(NewAPISource(nil, client.ConfigV1(), nil)).(*clusterOperatorsCheck)
+	check.now = func() time.Time { return now }
+	cfg := config.Config{
+		OperatorConditionMinAge:  5 * time.Minute,
+		OperatorConditionMinAges: map[string]time.Duration{"console": time.Minute},
+	}
+
+	result := check.Run(context.Background(), cfg)
+	got := map[string]bool{}
+	for _, ref := range result.Affected {
+		got[ref.Name] = true
+	}
+	if len(got) != 2 || !got["dns"] || !got["console"] {
+		t.Errorf("expected dns and console to be affected (ingress degraded too briefly), got %v", result.Affected)
+	}
+	if got := testutil.ToFloat64(metrics.ClusterOperatorConditionSeconds.WithLabelValues("ingress", "Degraded")); got != 120 {
+		t.Errorf("expected ingress Degraded condition age 120s, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.ClusterOperatorConditionSeconds.WithLabelValues("dns", "Available")); got != 600 {
+		t.Errorf("expected dns Available condition age 600s, got %v", got)
+	}
+}
+
+func TestClusterOperatorsCheck_Conditions(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	ingress := makeOperator("ingress", configv1.ConditionTrue, configv1.ConditionTrue)
+	ingress.Status.Conditions[0].Reason = "IngressDegraded"
+	ingress.Status.Conditions[0].Message = "The \"default\" ingress controller reports Degraded=True:\n  PodsScheduled=False"
+	dns := makeOperator("dns", configv1.ConditionFalse, configv1.ConditionFalse)
+	dns.Status.Conditions[1].Reason = "DNSUnavailable"
+	dns.Status.Conditions[1].LastTransitionTime = metav1.NewTime(now.Add(-time.Minute))
+	client := ocpfake.NewSimpleClientset(ingress, dns)
+
+	check := NewClusterOperatorsCheck(NewAPISource(nil, client.ConfigV1(), nil)).(*clusterOperatorsCheck)
+	check.now = func() time.Time { return now }
+	result := check.Run(context.Background(), config.Config{OperatorConditionMinAges: map[string]time.Duration{"dns": time.Hour}})
+
+	want := []Condition{{
+		Object:  ObjectRef{Kind: "ClusterOperator", Name: "ingress"},
+		Type:    "Degraded",
+		Status:  "True",
+		Reason:  "IngressDegraded",
+		Message: ingress.Status.Conditions[0].Message,
+	}}
+	if !reflect.DeepEqual(result.Conditions, want) {
+		t.Errorf("expected conditions %+v, got %+v", want, result.Conditions)
+	}
+	if len(result.Reasons) != 1 || !strings.Contains(result.Reasons[0], "Degraded=True (IngressDegraded): The \"default\" ingress controller reports Degraded=True: PodsScheduled=False") {
+		t.Errorf("expected the condition reason and message in the result, got %v", result.Reasons)
+	}
+
+	// The info metric covers failing conditions regardless of the minimum age.
+	if got := testutil.ToFloat64(metrics.ClusterOperatorConditionInfo.WithLabelValues("ingress", "Degraded", "True", "IngressDegraded", `The "default" ingress controller reports Degraded=True: PodsScheduled=False`)); got != 1 {
+		t.Errorf("expected ingress condition info=1, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.ClusterOperatorConditionInfo.WithLabelValues("dns", "Available", "False", "DNSUnavailable", "")); got != 1 {
+		t.Errorf("expected dns condition info=1, got %v", got)
+	}
+
+	// Recovered conditions lose their series.
+	ingress.Status.Conditions[0].Status = configv1.ConditionFalse
+	if _, err := client.ConfigV1().ClusterOperators().Update(context.Background(), ingress, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	check.Run(context.Background(), config.Config{})
+	if metrics.ClusterOperatorConditionInfo.DeleteLabelValues("ingress", "Degraded", "True", "IngressDegraded", `The "default" ingress controller reports Degraded=True: PodsScheduled=False`) {
+		t.Error("expected the ingress condition info series to be removed after ingress recovered")
+	}
+}
+
+func TestOperatorGroupCheck(t *testing.T) {
+	client := ocpfake.NewSimpleClientset(
+		makeOperator("kube-apiserver", configv1.ConditionFalse, configv1.ConditionTrue),
+		makeOperator("kube-scheduler", configv1.ConditionTrue, configv1.ConditionTrue),
+		makeOperator("etcd", configv1.ConditionFalse, configv1.ConditionTrue),
+		makeOperator("ingress", configv1.ConditionFalse, configv1.ConditionTrue),
+		makeOperator("dns", configv1.ConditionFalse, configv1.ConditionTrue),
+	)
+	source := NewAPISource(nil, client.ConfigV1(), nil)
+	cfg := config.Config{OperatorGroups: []config.OperatorGroup{
+		{Name: "control-plane", Operators: []string{"kube-*", "etcd"}, Severity: "critical"},
+		{Name: "ingress", Operators: []string{"ingress", "dns", "network"}, Severity: "warning"},
+	}}
+
+	check := NewOperatorGroupCheck(source, "control-plane")
+	if check.Name() != "operatorgroup/control-plane" {
+		t.Errorf("expected check name operatorgroup/control-plane, got %q", check.Name())
+	}
+	result := check.Run(context.Background(), cfg)
+	if result.Healthy() {
+		t.Fatal("expected control-plane group check to be unhealthy")
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "kube-scheduler" {
+		t.Errorf("expected kube-scheduler to be the only affected operator, got %v", result.Affected)
+	}
+
+	if result := NewOperatorGroupCheck(source, "ingress").Run(context.Background(), cfg); !result.Healthy() {
+		t.Errorf("expected ingress group check to be healthy, got %+v", result)
+	}
+
+	// The operators are read from the configuration on every run.
+	cfg.OperatorGroups[0].Operators = []string{"etcd"}
+	if result := check.Run(context.Background(), cfg); !result.Healthy() {
+		t.Errorf("expected control-plane group check without kube-scheduler to be healthy, got %+v", result)
+	}
+}
+
+func TestRegistry_SetOperatorGroups(t *testing.T) {
+	client := ocpfake.NewSimpleClientset(
+		makeOperator("kube-scheduler", configv1.ConditionTrue, configv1.ConditionTrue),
+		makeOperator("ingress", configv1.ConditionFalse, configv1.ConditionTrue),
+	)
+	reg := NewRegistry()
+	reg.source = NewAPISource(nil, client.ConfigV1(), nil)
+	cfg := config.Config{
+		Hysteresis: config.Hysteresis{UnhealthyCycles: 1, HealthyCycles: 1},
+		CheckHysteresis: map[string]config.Hysteresis{
+			"operatorgroup/control-plane": {UnhealthyCycles: 2, HealthyCycles: 1},
+		},
+		OperatorGroups: []config.OperatorGroup{
+			{Name: "control-plane", Operators: []string{"kube-*"}, Severity: "critical"},
+			{Name: "ingress", Operators: []string{"ingress"}, Severity: "warning"},
+		},
+	}
+	if err := reg.Validate(cfg); err != nil {
+		t.Fatalf("expected the group checks to be known, got: %v", err)
+	}
+	reg.SetOperatorGroups(cfg.OperatorGroups)
+	defer reg.SetOperatorGroups(nil)
+
+	controlPlane := metrics.ClusterOperatorGroupDegraded.WithLabelValues("control-plane", "critical")
+	RunChecks(context.Background(), reg, cfg)
+	if got := testutil.ToFloat64(controlPlane); got != 0 {
+		t.Errorf("expected control-plane group degraded=0 after one unhealthy cycle (hysteresis 2), got %v", got)
+	}
+	RunChecks(context.Background(), reg, cfg)
+	if got := testutil.ToFloat64(controlPlane); got != 1 {
+		t.Errorf("expected control-plane group degraded=1 after two unhealthy cycles, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.ClusterOperatorGroupDegraded.WithLabelValues("ingress", "warning")); got != 0 {
+		t.Errorf("expected ingress group degraded=0, got %v", got)
+	}
+
+	// Dropping a group, or changing its severity, deletes its series.
+	cfg.OperatorGroups = []config.OperatorGroup{{Name: "control-plane", Operators: []string{"kube-*"}, Severity: "warning"}}
+	if err := reg.Validate(cfg); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	reg.SetOperatorGroups(cfg.OperatorGroups)
+	if metrics.ClusterOperatorGroupDegraded.DeleteLabelValues("ingress", "warning") {
+		t.Error("expected the ingress group series to be deleted")
+	}
+	if metrics.ClusterOperatorGroupDegraded.DeleteLabelValues("control-plane", "critical") {
+		t.Error("expected the control-plane series with the old severity to be deleted")
+	}
+	if slices.Contains(reg.Names(), "operatorgroup/ingress") {
+		t.Errorf("expected the ingress group check to be unregistered, got %v", reg.Names())
+	}
+	if err := reg.Validate(config.Config{DisabledChecks: []string{"operatorgroup/ingress"}}); err == nil {
+		t.Error("expected error for the check of a group that is no longer defined, got nil")
+	}
+}
@@ -2,32 +2,46 @@ package checker
 
 import (
 	"context"
+	"fmt"
 	"log"
 
 	configv1 "github.com/openshift/api/config/v1"
-	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
-	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+	"github.com/openshift-cluster-check/health-checker/internal/config"
 )
 
-// CheckClusterVersion gets the ClusterVersion named "version" and updates
-// openshift_clusterversion_degraded: 1 if Degraded=True or Available=False, 0 otherwise.
+// clusterVersionCheck reports whether the ClusterVersion named "version" is
+// degraded or unavailable (openshift_clusterversion_degraded).
+type clusterVersionCheck struct {
+	source Source
+}
+
+// NewClusterVersionCheck returns the "clusterversion" check.
+func NewClusterVersionCheck(source Source) Check {
+	return &clusterVersionCheck{source: source}
+}
+
+// Name implements Check.
+func (c *clusterVersionCheck) Name() string { return "clusterversion" }
+
+// Run gets the ClusterVersion named "version" and reports it unhealthy if
+// Degraded=True or Available=False.
 //
-// On API error, the metric is set to 1 (fail-closed).
-func CheckClusterVersion(ctx context.Context, client configv1client.ConfigV1Interface) {
-	cv, err := client.ClusterVersions().Get(ctx, "version", metav1.GetOptions{})
+// On API error, the result is unknown.
+func (c *clusterVersionCheck) Run(ctx context.Context, _ config.Config) Result {
+	result := newResult(c.Name())
+
+	cv, err := c.source.GetClusterVersion(ctx, "version")
 	if err != nil {
-		log.Printf("WARNING: failed to get ClusterVersion 'version': %v — setting metric to 1 (fail-closed)", err)
-		metrics.ClusterVersionDegraded.Set(1)
-		return
+		log.Printf("WARNING: failed to get ClusterVersion 'version': %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to get ClusterVersion 'version': %w", err))
+		return result
 	}
 
 	if isClusterVersionDegraded(*cv) {
-		metrics.ClusterVersionDegraded.Set(1)
-	} else {
-		metrics.ClusterVersionDegraded.Set(0)
+		result.addFailure(ObjectRef{Kind: "ClusterVersion", Name: cv.Name}, "ClusterVersion 'version' is degraded or unavailable")
 	}
+	return result
 }
 
 // isClusterVersionDegraded returns true if the ClusterVersion has Degraded=True or Available=False.
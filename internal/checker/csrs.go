@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	certificatesv1 "k8s.io/api/certificates/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// csrsCheck reports whether any CertificateSigningRequest has been pending for
+// longer than cfg.CSRPendingMaxAge (openshift_csr_pending_too_long).
+type csrsCheck struct {
+	source Source
+
+	// counts holds the openshift_csr_pending_too_long_count series.
+	counts *metrics.Series
+
+	now func() time.Time
+}
+
+// NewCSRsCheck returns the "csrs" check.
+func NewCSRsCheck(source Source) Check {
+	return &csrsCheck{source: source, counts: metrics.NewSeries(metrics.CSRPendingTooLongCount), now: time.Now}
+}
+
+// Name implements Check.
+func (c *csrsCheck) Name() string { return "csrs" }
+
+// reset implements resetter.
+func (c *csrsCheck) reset() {
+	c.counts.Reset()
+}
+
+// Run lists all CertificateSigningRequests and reports every one that has been
+// neither approved, denied nor failed for longer than cfg.CSRPendingMaxAge.
+// Unapproved node CSRs keep nodes from joining or renewing their kubelet
+// certificates long before the nodes check notices. The number of such CSRs
+// per signer is published to openshift_csr_pending_too_long_count.
+//
+// On API error, the result is unknown.
+func (c *csrsCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+	now := c.now()
+
+	csrs, err := c.source.ListCertificateSigningRequests(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list CertificateSigningRequests: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list CertificateSigningRequests: %w", err))
+		return result
+	}
+
+	// Report the oldest first so the most likely culprit leads the reasons.
+	sort.SliceStable(csrs, func(i, j int) bool {
+		return csrs[i].CreationTimestamp.Before(&csrs[j].CreationTimestamp)
+	})
+
+	counts := make(map[string]int)
+	for _, csr := range csrs {
+		if !csrPending(csr) {
+			continue
+		}
+		age := now.Sub(csr.CreationTimestamp.Time)
+		if age <= cfg.CSRPendingMaxAge {
+			continue
+		}
+		counts[csr.Spec.SignerName]++
+		reason := fmt.Sprintf("CertificateSigningRequest %q for signer %q requested by %q has been pending for %s",
+			csr.Name, csr.Spec.SignerName, csr.Spec.Username, age.Truncate(time.Second))
+		log.Printf("WARNING: %s", reason)
+		result.addFailure(ObjectRef{Kind: "CertificateSigningRequest", Name: csr.Name}, reason)
+	}
+
+	samples := make([]metrics.Sample, 0, len(counts))
+	for signer, n := range counts {
+		samples = append(samples, metrics.Sample{Labels: []string{signer}, Value: float64(n)})
+	}
+	c.counts.Update(samples)
+
+	return result
+}
+
+// csrPending reports whether the CSR has not been approved, denied or failed.
+func csrPending(csr *certificatesv1.CertificateSigningRequest) bool {
+	for _, cond := range csr.Status.Conditions {
+		switch cond.Type {
+		case certificatesv1.CertificateApproved, certificatesv1.CertificateDenied, certificatesv1.CertificateFailed:
+			return false
+		}
+	}
+	return true
+}
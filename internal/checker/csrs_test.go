@@ -0,0 +1,94 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+func makeCSR(name, signer string, created time.Time, conditions ...certificatesv1.RequestConditionType) *certificatesv1.CertificateSigningRequest {
+	csr := &certificatesv1.CertificateSigningRequest{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       certificatesv1.CertificateSigningRequestSpec{SignerName: signer, Username: "system:node:" + name},
+	}
+	for _, condType := range conditions {
+		csr.Status.Conditions = append(csr.Status.Conditions, certificatesv1.CertificateSigningRequestCondition{Type: condType, Status: corev1.ConditionTrue})
+	}
+	return csr
+}
+
+func newTestCSRsCheck(client *fake.Clientset, now time.Time) *csrsCheck {
+	c := NewCSRsCheck(NewAPISource(client, nil, nil)).(*csrsCheck)
+	c.now = func() time.Time { return now }
+	return c
+}
+
+func TestCSRsCheck_PendingTooLong(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		makeCSR("old-serving", certificatesv1.KubeletServingSignerName, now.Add(-time.Hour)),
+		makeCSR("old-client", certificatesv1.KubeAPIServerClientKubeletSignerName, now.Add(-30*time.Minute)),
+		makeCSR("older-serving", certificatesv1.KubeletServingSignerName, now.Add(-2*time.Hour)),
+		makeCSR("recent", certificatesv1.KubeletServingSignerName, now.Add(-time.Minute)),
+		makeCSR("approved", certificatesv1.KubeletServingSignerName, now.Add(-time.Hour), certificatesv1.CertificateApproved),
+		makeCSR("denied", certificatesv1.KubeletServingSignerName, now.Add(-time.Hour), certificatesv1.CertificateDenied),
+	)
+
+	result := newTestCSRsCheck(client, now).Run(context.Background(), config.Config{CSRPendingMaxAge: 10 * time.Minute})
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	want := []string{"older-serving", "old-serving", "old-client"}
+	if len(result.Affected) != len(want) {
+		t.Fatalf("expected affected %v, got %v", want, result.Affected)
+	}
+	for i, name := range want {
+		if result.Affected[i].Name != name {
+			t.Errorf("affected[%d]: expected %q, got %q", i, name, result.Affected[i].Name)
+		}
+	}
+
+	if got := testutil.ToFloat64(metrics.CSRPendingTooLongCount.WithLabelValues(certificatesv1.KubeletServingSignerName)); got != 2 {
+		t.Errorf("expected 2 kubelet-serving CSRs pending too long, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.CSRPendingTooLongCount.WithLabelValues(certificatesv1.KubeAPIServerClientKubeletSignerName)); got != 1 {
+		t.Errorf("expected 1 kubelet client CSR pending too long, got %v", got)
+	}
+}
+
+func TestCSRsCheck_RecentOrHandledIsHealthy(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		makeCSR("recent", certificatesv1.KubeletServingSignerName, now.Add(-5*time.Minute)),
+		makeCSR("failed", certificatesv1.KubeletServingSignerName, now.Add(-time.Hour), certificatesv1.CertificateFailed),
+	)
+
+	result := newTestCSRsCheck(client, now).Run(context.Background(), config.Config{CSRPendingMaxAge: 10 * time.Minute})
+	if !result.Healthy() {
+		t.Errorf("expected healthy, got %+v", result)
+	}
+}
+
+func TestCSRsCheck_APIErrorIsUnknown(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "certificatesigningrequests", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	result := newTestCSRsCheck(client, time.Now()).Run(context.Background(), config.Config{CSRPendingMaxAge: time.Minute})
+	if result.Status != StatusUnknown {
+		t.Errorf("expected unknown, got %+v", result)
+	}
+}
@@ -0,0 +1,44 @@
+package checker
+
+import (
+	"context"
+	"errors"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Errors reported by the runner when a check could not produce its own result.
+var (
+	errTimedOut     = errors.New("check timed out")
+	errPanicked     = errors.New("check panicked")
+	errCancelled    = errors.New("check cancelled")
+	errStillRunning = errors.New("previous run has not finished")
+)
+
+// errorReason classifies a check error into a short, low-cardinality label
+// value for health_checker_check_errors_total.
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, errTimedOut), errors.Is(err, context.DeadlineExceeded),
+		apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		return "timeout"
+	case errors.Is(err, errPanicked):
+		return "panic"
+	case errors.Is(err, errCancelled), errors.Is(err, context.Canceled):
+		return "cancelled"
+	case errors.Is(err, errStillRunning):
+		return "still_running"
+	case errors.Is(err, errCacheNotSynced):
+		return "not_synced"
+	case apierrors.IsForbidden(err):
+		return "forbidden"
+	case apierrors.IsUnauthorized(err):
+		return "unauthorized"
+	case apierrors.IsNotFound(err):
+		return "not_found"
+	case apierrors.IsTooManyRequests(err), apierrors.IsServiceUnavailable(err):
+		return "unavailable"
+	default:
+		return "api_error"
+	}
+}
@@ -0,0 +1,35 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestErrorReason(t *testing.T) {
+	nodes := schema.GroupResource{Resource: "nodes"}
+	tests := []struct {
+		err  error
+		want string
+	}{
+		{fmt.Errorf("%w after 5s", errTimedOut), "timeout"},
+		{fmt.Errorf("failed to list Nodes: %w", context.DeadlineExceeded), "timeout"},
+		{fmt.Errorf("%w: boom", errPanicked), "panic"},
+		{errCancelled, "cancelled"},
+		{errStillRunning, "still_running"},
+		{fmt.Errorf("failed to list Nodes: %w", apierrors.NewForbidden(nodes, "", errors.New("rbac"))), "forbidden"},
+		{apierrors.NewUnauthorized("expired token"), "unauthorized"},
+		{apierrors.NewNotFound(nodes, "n1"), "not_found"},
+		{apierrors.NewServiceUnavailable("down"), "unavailable"},
+		{errors.New("connection refused"), "api_error"},
+	}
+	for _, tt := range tests {
+		if got := errorReason(tt.err); got != tt.want {
+			t.Errorf("errorReason(%v) = %q, want %q", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,396 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	ocpclientset "github.com/openshift/client-go/config/clientset/versioned"
+	configinformers "github.com/openshift/client-go/config/informers/externalversions"
+	configlisters "github.com/openshift/client-go/config/listers/config/v1"
+	mcfgclientset "github.com/openshift/client-go/machineconfiguration/clientset/versioned"
+	mcfginformers "github.com/openshift/client-go/machineconfiguration/informers/externalversions"
+	mcfglisters "github.com/openshift/client-go/machineconfiguration/listers/machineconfiguration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	appslisters "k8s.io/client-go/listers/apps/v1"
+	batchlisters "k8s.io/client-go/listers/batch/v1"
+	certificateslisters "k8s.io/client-go/listers/certificates/v1"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+)
+
+// errCacheNotSynced is returned by InformerSource until every informer has
+// completed its initial List, so checks report unknown rather than evaluate a
+// partially populated cache.
+var errCacheNotSynced = errors.New("informer cache has not synced yet")
+
+// InformerSource is a Source backed by shared informers. After Start, objects
+// are served from the local cache, kept current by a single watch per
+// resource, instead of a List call per check run. Every change to a watched
+// object in a system namespace (or to a cluster-scoped object) is signalled on
+// Changes so callers can re-run checks promptly.
+//
+// Cached objects are trimmed to the fields the checks read (see trimObject):
+// the pod cache is cluster-wide, because system namespaces are matched by
+// prefix, which cannot be expressed as a watch filter.
+//
+// Secrets are the exception: they are read with List calls so that private
+// keys are never held in memory between cycles.
+type InformerSource struct {
+	live Source
+
+	k8sClient  kubernetes.Interface
+	ocpClient  ocpclientset.Interface
+	mcfgClient mcfgclientset.Interface
+
+	// caches holds the informers started by the latest Start once they have
+	// synced; nil before then and after that Start's context is cancelled.
+	caches  atomic.Pointer[informerCaches]
+	changes chan struct{}
+
+	// config returns the current configuration, for the system namespace rules.
+	config func() config.Config
+}
+
+// informerCaches are the informers run by one call to Start.
+type informerCaches struct {
+	kubeFactory informers.SharedInformerFactory
+	ocpFactory  configinformers.SharedInformerFactory
+	mcfgFactory mcfginformers.SharedInformerFactory
+
+	operators  configlisters.ClusterOperatorLister
+	versions   configlisters.ClusterVersionLister
+	nodes      corelisters.NodeLister
+	namespaces corelisters.NamespaceLister
+	pods       corelisters.PodLister
+	deploys    appslisters.DeploymentLister
+	daemonSets appslisters.DaemonSetLister
+	statefuls  appslisters.StatefulSetLister
+	jobs       batchlisters.JobLister
+	pools      mcfglisters.MachineConfigPoolLister
+	csrs       certificateslisters.CertificateSigningRequestLister
+
+	synced []cache.InformerSynced
+}
+
+// NewInformerSource returns a Source for every resource the built-in checks
+// read. Informers do not run until Start is called. current returns the
+// configuration in effect, whose system namespace rules decide which changes
+// to namespaced objects are signalled on Changes.
+func NewInformerSource(k8sClient kubernetes.Interface, ocpClient ocpclientset.Interface, mcfgClient mcfgclientset.Interface, current func() config.Config) *InformerSource {
+	return &InformerSource{
+		live:       NewAPISource(k8sClient, nil, nil),
+		k8sClient:  k8sClient,
+		ocpClient:  ocpClient,
+		mcfgClient: mcfgClient,
+		changes:    make(chan struct{}, 1),
+		config:     current,
+	}
+}
+
+// newCaches creates a fresh set of informers. Informers cannot be restarted
+// once stopped, so every Start needs its own.
+func (s *InformerSource) newCaches() (*informerCaches, error) {
+	trim := func(obj interface{}) (interface{}, error) {
+		trimObject(obj)
+		return obj, nil
+	}
+
+	c := &informerCaches{
+		kubeFactory: informers.NewSharedInformerFactoryWithOptions(s.k8sClient, 0, informers.WithTransform(trim)),
+		ocpFactory:  configinformers.NewSharedInformerFactoryWithOptions(s.ocpClient, 0, configinformers.WithTransform(trim)),
+		mcfgFactory: mcfginformers.NewSharedInformerFactoryWithOptions(s.mcfgClient, 0, mcfginformers.WithTransform(trim)),
+	}
+
+	operatorInformer := c.ocpFactory.Config().V1().ClusterOperators()
+	versionInformer := c.ocpFactory.Config().V1().ClusterVersions()
+	nodeInformer := c.kubeFactory.Core().V1().Nodes()
+	namespaceInformer := c.kubeFactory.Core().V1().Namespaces()
+	podInformer := c.kubeFactory.Core().V1().Pods()
+	deploymentInformer := c.kubeFactory.Apps().V1().Deployments()
+	daemonSetInformer := c.kubeFactory.Apps().V1().DaemonSets()
+	statefulSetInformer := c.kubeFactory.Apps().V1().StatefulSets()
+	jobInformer := c.kubeFactory.Batch().V1().Jobs()
+	poolInformer := c.mcfgFactory.Machineconfiguration().V1().MachineConfigPools()
+	csrInformer := c.kubeFactory.Certificates().V1().CertificateSigningRequests()
+
+	c.operators = operatorInformer.Lister()
+	c.versions = versionInformer.Lister()
+	c.nodes = nodeInformer.Lister()
+	c.namespaces = namespaceInformer.Lister()
+	c.pods = podInformer.Lister()
+	c.deploys = deploymentInformer.Lister()
+	c.daemonSets = daemonSetInformer.Lister()
+	c.statefuls = statefulSetInformer.Lister()
+	c.jobs = jobInformer.Lister()
+	c.pools = poolInformer.Lister()
+	c.csrs = csrInformer.Lister()
+
+	// Changes outside system namespaces (e.g. user pods) do not affect any
+	// check, so they must not trigger a cycle.
+	handler := cache.FilteringResourceEventHandler{
+		FilterFunc: s.inSystemNamespace,
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(interface{}) { s.notify() },
+			UpdateFunc: func(interface{}, interface{}) { s.notify() },
+			DeleteFunc: func(interface{}) { s.notify() },
+		},
+	}
+	for _, informer := range []cache.SharedIndexInformer{
+		operatorInformer.Informer(),
+		versionInformer.Informer(),
+		nodeInformer.Informer(),
+		namespaceInformer.Informer(),
+		podInformer.Informer(),
+		deploymentInformer.Informer(),
+		daemonSetInformer.Informer(),
+		statefulSetInformer.Informer(),
+		jobInformer.Informer(),
+		poolInformer.Informer(),
+		csrInformer.Informer(),
+	} {
+		if _, err := informer.AddEventHandler(handler); err != nil {
+			return nil, fmt.Errorf("failed to add informer event handler: %w", err)
+		}
+		c.synced = append(c.synced, informer.HasSynced)
+	}
+	return c, nil
+}
+
+// Start runs a fresh set of informers until ctx is cancelled and waits for
+// their initial List to complete. It returns an error if ctx is cancelled
+// before then. Once ctx is cancelled, reads fail until Start is called again
+// (e.g. when this replica becomes leader again) and its caches have synced.
+func (s *InformerSource) Start(ctx context.Context) error {
+	c, err := s.newCaches()
+	if err != nil {
+		return err
+	}
+	c.kubeFactory.Start(ctx.Done())
+	c.ocpFactory.Start(ctx.Done())
+	c.mcfgFactory.Start(ctx.Done())
+	go func() {
+		<-ctx.Done()
+		s.caches.CompareAndSwap(c, nil)
+		c.kubeFactory.Shutdown()
+		c.ocpFactory.Shutdown()
+		c.mcfgFactory.Shutdown()
+	}()
+
+	log.Println("INFO: Waiting for informer caches to sync...")
+	if !cache.WaitForCacheSync(ctx.Done(), c.synced...) {
+		return errCacheNotSynced
+	}
+	s.caches.Store(c)
+	log.Println("INFO: Informer caches synced.")
+	return nil
+}
+
+// Changes returns a channel that receives a value after one or more watched
+// objects change. Notifications are coalesced: at most one is pending at a time.
+func (s *InformerSource) Changes() <-chan struct{} {
+	return s.changes
+}
+
+// notify records a change without blocking the informer's event delivery.
+func (s *InformerSource) notify() {
+	select {
+	case s.changes <- struct{}{}:
+	default:
+	}
+}
+
+// inSystemNamespace reports whether a watched object is relevant to the
+// checks: it is cluster-scoped, or it is (or is in) a system namespace.
+func (s *InformerSource) inSystemNamespace(obj interface{}) bool {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	accessor, err := meta.Accessor(obj)
+	if err != nil {
+		return true
+	}
+	namespace := accessor.GetNamespace()
+	if _, ok := obj.(*corev1.Namespace); ok {
+		namespace = accessor.GetName()
+	} else if namespace == "" {
+		return true
+	}
+	return IsSystemNamespace(namespace, s.config())
+}
+
+// trimObject drops the fields of a cached object that no check reads, to
+// bound the memory held by the cluster-wide caches: managedFields and
+// annotations of every object, pod specs, the pod templates of workload
+// controllers and Jobs, and the request of CSRs.
+func trimObject(obj interface{}) {
+	if accessor, err := meta.Accessor(obj); err == nil {
+		accessor.SetManagedFields(nil)
+		accessor.SetAnnotations(nil)
+	}
+	switch o := obj.(type) {
+	case *corev1.Pod:
+		o.Spec = corev1.PodSpec{}
+	case *appsv1.Deployment:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	case *appsv1.DaemonSet:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	case *appsv1.StatefulSet:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+		o.Spec.VolumeClaimTemplates = nil
+	case *batchv1.Job:
+		o.Spec.Template = corev1.PodTemplateSpec{}
+	case *certificatesv1.CertificateSigningRequest:
+		o.Spec.Request = nil
+	}
+}
+
+// current returns the synced caches of the running informers, or
+// errCacheNotSynced if there are none.
+func (s *InformerSource) current() (*informerCaches, error) {
+	c := s.caches.Load()
+	if c == nil {
+		return nil, errCacheNotSynced
+	}
+	return c, nil
+}
+
+func (s *InformerSource) ListClusterOperators(context.Context) ([]*configv1.ClusterOperator, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.operators.List(labels.Everything())
+}
+
+func (s *InformerSource) GetClusterVersion(_ context.Context, name string) (*configv1.ClusterVersion, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.versions.Get(name)
+}
+
+func (s *InformerSource) ListNodes(context.Context) ([]*corev1.Node, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.nodes.List(labels.Everything())
+}
+
+func (s *InformerSource) ListNamespaces(context.Context) ([]*corev1.Namespace, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.namespaces.List(labels.Everything())
+}
+
+func (s *InformerSource) ListPods(_ context.Context, namespace string) ([]*corev1.Pod, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.pods.Pods(namespace).List(labels.Everything())
+}
+
+func (s *InformerSource) ListDeployments(_ context.Context, namespace string) ([]*appsv1.Deployment, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.deploys.Deployments(namespace).List(labels.Everything())
+}
+
+func (s *InformerSource) ListDaemonSets(_ context.Context, namespace string) ([]*appsv1.DaemonSet, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.daemonSets.DaemonSets(namespace).List(labels.Everything())
+}
+
+func (s *InformerSource) ListStatefulSets(_ context.Context, namespace string) ([]*appsv1.StatefulSet, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.statefuls.StatefulSets(namespace).List(labels.Everything())
+}
+
+func (s *InformerSource) ListJobs(_ context.Context, namespace string) ([]*batchv1.Job, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.jobs.Jobs(namespace).List(labels.Everything())
+}
+
+func (s *InformerSource) ListMachineConfigPools(context.Context) ([]*mcfgv1.MachineConfigPool, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.pools.List(labels.Everything())
+}
+
+func (s *InformerSource) ListCertificateSigningRequests(context.Context) ([]*certificatesv1.CertificateSigningRequest, error) {
+	c, err := s.current()
+	if err != nil {
+		return nil, err
+	}
+	return c.csrs.List(labels.Everything())
+}
+
+// ListTLSSecrets reads from the API server, not a cache (see InformerSource).
+func (s *InformerSource) ListTLSSecrets(ctx context.Context, namespace string) ([]*corev1.Secret, error) {
+	return s.live.ListTLSSecrets(ctx, namespace)
+}
+
+// Debounce forwards a single value on the returned channel delay after the
+// first of one or more values on in, so a burst of changes (e.g. a rolling
+// restart) triggers one check cycle rather than one per event. Values that
+// arrive during the delay are folded into the same notification, so continuous
+// churn triggers at most one cycle per delay without postponing it forever.
+func Debounce(ctx context.Context, in <-chan struct{}, delay time.Duration) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	go func() {
+		timer := time.NewTimer(delay)
+		timer.Stop()
+		pending := false
+		for {
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-in:
+				if !pending {
+					pending = true
+					timer.Reset(delay)
+				}
+			case <-timer.C:
+				pending = false
+				select {
+				case out <- struct{}{}:
+				default:
+				}
+			}
+		}
+	}()
+	return out
+}
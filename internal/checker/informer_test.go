@@ -0,0 +1,173 @@
+package checker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	ocpfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	mcfgfake "github.com/openshift/client-go/machineconfiguration/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+)
+
+func TestInformerSource_ServesChecksFromCache(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	k8sClient := fake.NewSimpleClientset(makeNode("worker-0", corev1.ConditionFalse, "worker"))
+	ocpClient := ocpfake.NewSimpleClientset(makeOperator("etcd", configv1.ConditionTrue, configv1.ConditionTrue))
+
+	source := NewInformerSource(k8sClient, ocpClient, mcfgfake.NewSimpleClientset(), func() config.Config { return config.Config{} })
+
+	if _, err := source.ListNodes(ctx); err == nil {
+		t.Error("expected an error before the caches have synced")
+	}
+
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("expected caches to sync, got: %v", err)
+	}
+
+	result := NewNodesCheck(source).Run(ctx, config.Config{})
+	if result.Healthy() || result.Affected[0].Name != "worker-0" {
+		t.Errorf("expected worker-0 to be reported not ready from the cache, got %+v", result)
+	}
+	result = NewEtcdCheck(source).Run(ctx, config.Config{})
+	if result.Healthy() {
+		t.Error("expected etcd to be reported degraded from the cache")
+	}
+}
+
+func TestInformerSource_SignalsChanges(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	k8sClient := fake.NewSimpleClientset()
+	source := NewInformerSource(k8sClient, ocpfake.NewSimpleClientset(), mcfgfake.NewSimpleClientset(), func() config.Config { return config.Config{} })
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("expected caches to sync, got: %v", err)
+	}
+	// Drain any notification from the initial List.
+	select {
+	case <-source.Changes():
+	default:
+	}
+
+	node := makeNode("worker-1", corev1.ConditionTrue, "worker")
+	if _, err := k8sClient.CoreV1().Nodes().Create(ctx, node, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create node: %v", err)
+	}
+
+	select {
+	case <-source.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change notification after creating a node")
+	}
+}
+
+func TestInformerSource_Restart(t *testing.T) {
+	k8sClient := fake.NewSimpleClientset(makeNode("worker-0", corev1.ConditionTrue, "worker"))
+	source := NewInformerSource(k8sClient, ocpfake.NewSimpleClientset(), mcfgfake.NewSimpleClientset(), func() config.Config { return config.Config{} })
+
+	// A first leadership term.
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("expected caches to sync, got: %v", err)
+	}
+	cancel()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := source.ListNodes(context.Background()); err != nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expected reads to fail once the informers are stopped")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// A second term starts fresh informers.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("expected caches to sync again, got: %v", err)
+	}
+	nodes, err := source.ListNodes(ctx)
+	if err != nil || len(nodes) != 1 {
+		t.Errorf("expected the node from the restarted cache, got %v, %v", nodes, err)
+	}
+}
+
+func TestInformerSource_IgnoresUserNamespaces(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	k8sClient := fake.NewSimpleClientset()
+	cfg := config.Config{SystemNamespacePrefixes: []string{"openshift-"}}
+	source := NewInformerSource(k8sClient, ocpfake.NewSimpleClientset(), mcfgfake.NewSimpleClientset(), func() config.Config { return cfg })
+	if err := source.Start(ctx); err != nil {
+		t.Fatalf("expected caches to sync, got: %v", err)
+	}
+	select {
+	case <-source.Changes():
+	default:
+	}
+
+	userPod := makePod("app-1", "my-app", corev1.PodRunning, nil)
+	if _, err := k8sClient.CoreV1().Pods("my-app").Create(ctx, &userPod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	select {
+	case <-source.Changes():
+		t.Fatal("expected no change notification for a pod in a user namespace")
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	systemPod := makePod("router-1", "openshift-ingress", corev1.PodRunning, nil)
+	systemPod.Annotations = map[string]string{"example.com/large": "value"}
+	systemPod.Spec.Containers = []corev1.Container{{Name: "router", Image: "router:latest"}}
+	if _, err := k8sClient.CoreV1().Pods("openshift-ingress").Create(ctx, &systemPod, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("failed to create pod: %v", err)
+	}
+	select {
+	case <-source.Changes():
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a change notification for a pod in a system namespace")
+	}
+
+	pods, err := source.ListPods(ctx, "openshift-ingress")
+	if err != nil || len(pods) != 1 {
+		t.Fatalf("expected the system pod in the cache, got %v, %v", pods, err)
+	}
+	if pods[0].Annotations != nil || len(pods[0].Spec.Containers) != 0 || pods[0].Status.Phase != corev1.PodRunning {
+		t.Errorf("expected the cached pod to be trimmed to its status, got %+v", pods[0])
+	}
+}
+
+func TestDebounce_CoalescesBurst(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	in := make(chan struct{})
+	out := Debounce(ctx, in, 50*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		in <- struct{}{}
+	}
+
+	select {
+	case <-out:
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected a debounced notification")
+	}
+
+	select {
+	case <-out:
+		t.Fatal("expected a burst of changes to produce a single notification")
+	case <-time.After(150 * time.Millisecond):
+	}
+}
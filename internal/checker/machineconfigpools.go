@@ -0,0 +1,101 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// machineConfigPoolDegradedConditions are the MachineConfigPool conditions
+// that make a pool degraded when True. Degraded summarises the others, but a
+// pool can report NodeDegraded or RenderDegraded before Degraded catches up.
+var machineConfigPoolDegradedConditions = []mcfgv1.MachineConfigPoolConditionType{
+	mcfgv1.MachineConfigPoolDegraded,
+	mcfgv1.MachineConfigPoolNodeDegraded,
+	mcfgv1.MachineConfigPoolRenderDegraded,
+}
+
+// machineConfigPoolsCheck reports whether any MachineConfigPool is degraded
+// (openshift_machineconfigpools_degraded).
+type machineConfigPoolsCheck struct {
+	source Source
+
+	// machines holds the openshift_machineconfigpool_machines series.
+	machines *metrics.Series
+}
+
+// NewMachineConfigPoolsCheck returns the "machineconfigpools" check.
+func NewMachineConfigPoolsCheck(source Source) Check {
+	return &machineConfigPoolsCheck{source: source, machines: metrics.NewSeries(metrics.MachineConfigPoolMachines)}
+}
+
+// Name implements Check.
+func (c *machineConfigPoolsCheck) Name() string { return "machineconfigpools" }
+
+// reset implements resetter.
+func (c *machineConfigPoolsCheck) reset() {
+	c.machines.Reset()
+}
+
+// Run lists all MachineConfigPools and reports every pool with Degraded,
+// NodeDegraded or RenderDegraded True. The machine counts of every pool are
+// published to openshift_machineconfigpool_machines.
+//
+// On API error, the result is unknown.
+func (c *machineConfigPoolsCheck) Run(ctx context.Context, _ config.Config) Result {
+	result := newResult(c.Name())
+
+	pools, err := c.source.ListMachineConfigPools(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list MachineConfigPools: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list MachineConfigPools: %w", err))
+		return result
+	}
+
+	samples := make([]metrics.Sample, 0, 5*len(pools))
+	for _, pool := range pools {
+		status := pool.Status
+		for _, count := range []struct {
+			state string
+			value int32
+		}{
+			{"total", status.MachineCount},
+			{"updated", status.UpdatedMachineCount},
+			{"ready", status.ReadyMachineCount},
+			{"unavailable", status.UnavailableMachineCount},
+			{"degraded", status.DegradedMachineCount},
+		} {
+			samples = append(samples, metrics.Sample{Labels: []string{pool.Name, count.state}, Value: float64(count.value)})
+		}
+
+		if degraded := machineConfigPoolDegradedReasons(*pool); len(degraded) > 0 {
+			result.addFailure(ObjectRef{Kind: "MachineConfigPool", Name: pool.Name},
+				fmt.Sprintf("MachineConfigPool %q is degraded (%s)", pool.Name, strings.Join(degraded, ", ")))
+		}
+	}
+	c.machines.Update(samples)
+
+	return result
+}
+
+// machineConfigPoolDegradedReasons returns the degraded conditions that are
+// True on the pool, in the order of machineConfigPoolDegradedConditions.
+func machineConfigPoolDegradedReasons(pool mcfgv1.MachineConfigPool) []string {
+	var reasons []string
+	for _, condType := range machineConfigPoolDegradedConditions {
+		for _, cond := range pool.Status.Conditions {
+			if cond.Type == condType && cond.Status == corev1.ConditionTrue {
+				log.Printf("WARNING: MachineConfigPool %q is %s: %s", pool.Name, cond.Type, cond.Message)
+				reasons = append(reasons, string(cond.Type))
+			}
+		}
+	}
+	return reasons
+}
@@ -0,0 +1,87 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	mcfgfake "github.com/openshift/client-go/machineconfiguration/clientset/versioned/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+func makePool(name string, machines, updated int32, degraded ...mcfgv1.MachineConfigPoolConditionType) *mcfgv1.MachineConfigPool {
+	pool := &mcfgv1.MachineConfigPool{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: mcfgv1.MachineConfigPoolStatus{
+			MachineCount:        machines,
+			UpdatedMachineCount: updated,
+			ReadyMachineCount:   updated,
+		},
+	}
+	for _, condType := range degraded {
+		pool.Status.Conditions = append(pool.Status.Conditions, mcfgv1.MachineConfigPoolCondition{Type: condType, Status: corev1.ConditionTrue})
+	}
+	return pool
+}
+
+func TestMachineConfigPoolsCheck_Degraded(t *testing.T) {
+	client := mcfgfake.NewSimpleClientset(
+		makePool("master", 3, 3),
+		makePool("worker", 5, 4, mcfgv1.MachineConfigPoolNodeDegraded),
+		makePool("infra", 2, 2, mcfgv1.MachineConfigPoolRenderDegraded, mcfgv1.MachineConfigPoolDegraded),
+	)
+
+	result := NewMachineConfigPoolsCheck(NewAPISource(nil, nil, client.MachineconfigurationV1())).Run(context.Background(), config.Config{})
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	if len(result.Affected) != 2 {
+		t.Fatalf("expected 2 affected pools, got %v", result.Affected)
+	}
+	for _, ref := range result.Affected {
+		if ref.Kind != "MachineConfigPool" || ref.Name == "master" {
+			t.Errorf("unexpected affected object %v", ref)
+		}
+	}
+
+	if got := testutil.ToFloat64(metrics.MachineConfigPoolMachines.WithLabelValues("worker", "updated")); got != 4 {
+		t.Errorf("expected 4 updated worker machines, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.MachineConfigPoolMachines.WithLabelValues("master", "total")); got != 3 {
+		t.Errorf("expected 3 master machines, got %v", got)
+	}
+}
+
+func TestMachineConfigPoolsCheck_UpdatingIsHealthy(t *testing.T) {
+	pool := makePool("worker", 5, 2)
+	pool.Status.Conditions = []mcfgv1.MachineConfigPoolCondition{
+		{Type: mcfgv1.MachineConfigPoolUpdating, Status: corev1.ConditionTrue},
+		{Type: mcfgv1.MachineConfigPoolDegraded, Status: corev1.ConditionFalse},
+	}
+	client := mcfgfake.NewSimpleClientset(pool)
+
+	result := NewMachineConfigPoolsCheck(NewAPISource(nil, nil, client.MachineconfigurationV1())).Run(context.Background(), config.Config{})
+	if !result.Healthy() {
+		t.Errorf("expected an updating pool that is not degraded to be healthy, got %+v", result)
+	}
+}
+
+func TestMachineConfigPoolsCheck_APIErrorIsUnknown(t *testing.T) {
+	client := mcfgfake.NewSimpleClientset()
+	client.PrependReactor("list", "machineconfigpools", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	result := NewMachineConfigPoolsCheck(NewAPISource(nil, nil, client.MachineconfigurationV1())).Run(context.Background(), config.Config{})
+	if result.Status != StatusUnknown || result.Err == nil {
+		t.Errorf("expected unknown result with error, got %+v", result)
+	}
+}
@@ -2,41 +2,129 @@ package checker
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
+	"sort"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
 
+	"github.com/openshift-cluster-check/health-checker/internal/config"
 	"github.com/openshift-cluster-check/health-checker/internal/metrics"
 )
 
-// CheckNodes lists all Nodes and updates openshift_nodes_not_ready:
-//   - 1 if any node has condition Ready != True
-//   - 0 if all nodes are ready
+// nodesCheck reports whether any node role has more NotReady Nodes than it
+// tolerates (openshift_nodes_not_ready).
+type nodesCheck struct {
+	source Source
+
+	// ready holds the openshift_node_ready series.
+	ready *metrics.Series
+
+	// roleNotReady holds the openshift_node_role_not_ready series.
+	roleNotReady *metrics.Series
+
+	// roleNodes holds the openshift_node_role_nodes series.
+	roleNodes *metrics.Series
+}
+
+// NewNodesCheck returns the "nodes" check.
+func NewNodesCheck(source Source) Check {
+	return &nodesCheck{
+		source:       source,
+		ready:        metrics.NewSeries(metrics.NodeReady),
+		roleNotReady: metrics.NewSeries(metrics.NodeRoleNotReady),
+		roleNodes:    metrics.NewSeries(metrics.NodeRoleNodes),
+	}
+}
+
+// Name implements Check.
+func (c *nodesCheck) Name() string { return "nodes" }
+
+// reset implements resetter.
+func (c *nodesCheck) reset() {
+	c.ready.Reset()
+	c.roleNotReady.Reset()
+	c.roleNodes.Reset()
+}
+
+// Run lists all Nodes, groups them by role (see nodeRoleGroup) and reports
+// every role with more NotReady nodes than cfg.NodeThresholdFor allows, along
+// with its NotReady nodes. With the default threshold of 0, any NotReady node
+// is reported. The readiness of every node is published to
+// openshift_node_ready, and the state of every role to
+// openshift_node_role_not_ready and openshift_node_role_nodes.
 //
-// On API error, the metric is set to 1 (fail-closed).
-func CheckNodes(ctx context.Context, client kubernetes.Interface) {
-	nodes, err := client.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+// On API error, the result is unknown.
+func (c *nodesCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+
+	nodes, err := c.source.ListNodes(ctx)
 	if err != nil {
-		log.Printf("WARNING: failed to list Nodes: %v — setting metric to 1 (fail-closed)", err)
-		metrics.NodesNotReady.Set(1)
-		return
+		log.Printf("WARNING: failed to list Nodes: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Nodes: %w", err))
+		return result
 	}
 
-	notReady := false
-	for _, node := range nodes.Items {
-		if !isNodeReady(node) {
-			notReady = true
-			log.Printf("WARNING: Node %q is not Ready", node.Name)
+	type roleState struct {
+		total    int
+		notReady []string
+	}
+	roles := map[string]*roleState{}
+
+	samples := make([]metrics.Sample, 0, len(nodes))
+	for _, node := range nodes {
+		ready := isNodeReady(*node)
+		samples = append(samples, metrics.Sample{
+			Labels: []string{node.Name, strings.Join(nodeRoles(*node), ",")},
+			Value:  boolToFloat(ready),
+		})
+
+		role := nodeRoleGroup(*node)
+		state, ok := roles[role]
+		if !ok {
+			state = &roleState{}
+			roles[role] = state
+		}
+		state.total++
+		if !ready {
+			log.Printf("WARNING: Node %q (role %s) is not Ready", node.Name, role)
+			state.notReady = append(state.notReady, node.Name)
 		}
 	}
+	c.ready.Update(samples)
 
-	if notReady {
-		metrics.NodesNotReady.Set(1)
-	} else {
-		metrics.NodesNotReady.Set(0)
+	names := make([]string, 0, len(roles))
+	for role := range roles {
+		names = append(names, role)
 	}
+	sort.Strings(names)
+
+	roleSamples := make([]metrics.Sample, 0, len(roles))
+	countSamples := make([]metrics.Sample, 0, 2*len(roles))
+	for _, role := range names {
+		state := roles[role]
+		threshold := cfg.NodeThresholdFor(role)
+		exceeded := threshold.Exceeded(len(state.notReady), state.total)
+		roleSamples = append(roleSamples, metrics.Sample{Labels: []string{role}, Value: boolToFloat(exceeded)})
+		countSamples = append(countSamples,
+			metrics.Sample{Labels: []string{role, "total"}, Value: float64(state.total)},
+			metrics.Sample{Labels: []string{role, "not_ready"}, Value: float64(len(state.notReady))},
+		)
+		if !exceeded {
+			continue
+		}
+		log.Printf("WARNING: %d of %d %s nodes are not Ready (tolerated: %s)", len(state.notReady), state.total, role, threshold)
+		for _, name := range state.notReady {
+			result.addFailure(ObjectRef{Kind: "Node", Name: name},
+				fmt.Sprintf("Node %q is not Ready (%d of %d %s nodes not Ready, tolerated: %s)", name, len(state.notReady), state.total, role, threshold))
+		}
+	}
+	c.roleNotReady.Update(roleSamples)
+	c.roleNodes.Update(countSamples)
+
+	return result
 }
 
 // isNodeReady returns true if the node has condition Ready=True.
@@ -50,3 +138,137 @@ func isNodeReady(node corev1.Node) bool {
 	// No Ready condition found — treat as not ready
 	return false
 }
+
+// nodeProblemsCheck reports whether any Node is under pressure, has
+// NetworkUnavailable, is cordoned or carries one of the configured taints
+// (openshift_nodes_impaired). It is separate from the nodes check so that
+// openshift_nodes_not_ready keeps meaning "not Ready" only.
+type nodeProblemsCheck struct {
+	source Source
+
+	// problems holds the openshift_node_problem series.
+	problems *metrics.Series
+}
+
+// NewNodeProblemsCheck returns the "nodeproblems" check.
+func NewNodeProblemsCheck(source Source) Check {
+	return &nodeProblemsCheck{source: source, problems: metrics.NewSeries(metrics.NodeProblem)}
+}
+
+// Name implements Check.
+func (c *nodeProblemsCheck) Name() string { return "nodeproblems" }
+
+// reset implements resetter.
+func (c *nodeProblemsCheck) reset() {
+	c.problems.Reset()
+}
+
+// Run lists all Nodes and reports every node with one of cfg.NodeProblems or
+// cfg.NodeProblemTaints. Each problem is published as its own
+// openshift_node_problem series.
+//
+// On API error, the result is unknown.
+func (c *nodeProblemsCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+
+	nodes, err := c.source.ListNodes(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list Nodes: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Nodes: %w", err))
+		return result
+	}
+
+	var samples []metrics.Sample
+	for _, node := range nodes {
+		problems := nodeProblems(*node, cfg)
+		if len(problems) == 0 {
+			continue
+		}
+		for _, problem := range problems {
+			samples = append(samples, metrics.Sample{Labels: []string{node.Name, problem}, Value: 1})
+		}
+		reason := fmt.Sprintf("Node %q has %s", node.Name, strings.Join(problems, ", "))
+		log.Printf("WARNING: %s", reason)
+		result.addFailure(ObjectRef{Kind: "Node", Name: node.Name}, reason)
+	}
+	c.problems.Update(samples)
+
+	return result
+}
+
+// nodeProblems returns the problems of the node that are enabled in cfg:
+// conditions from cfg.NodeProblems that are True, "Unschedulable" if the node
+// is cordoned and that problem is enabled, and "Taint:<key>" for every taint
+// matching cfg.NodeProblemTaints.
+func nodeProblems(node corev1.Node, cfg config.Config) []string {
+	var problems []string
+	for _, problem := range cfg.NodeProblems {
+		if problem == "Unschedulable" {
+			if node.Spec.Unschedulable {
+				problems = append(problems, problem)
+			}
+			continue
+		}
+		for _, cond := range node.Status.Conditions {
+			if string(cond.Type) == problem && cond.Status == corev1.ConditionTrue {
+				problems = append(problems, problem)
+			}
+		}
+	}
+	for _, taint := range node.Spec.Taints {
+		// A key can be set with several effects; report it once.
+		if problem := "Taint:" + taint.Key; taintMatches(taint, cfg.NodeProblemTaints) && !slices.Contains(problems, problem) {
+			problems = append(problems, problem)
+		}
+	}
+	return problems
+}
+
+// taintMatches reports whether the taint matches any of patterns, each "key"
+// (any effect) or "key:Effect".
+func taintMatches(taint corev1.Taint, patterns []string) bool {
+	for _, pattern := range patterns {
+		key, effect, hasEffect := strings.Cut(pattern, ":")
+		if key == taint.Key && (!hasEffect || effect == string(taint.Effect)) {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeRoleLabelPrefix is the label prefix Kubernetes and OpenShift use to mark node roles
+// (e.g. node-role.kubernetes.io/worker).
+const nodeRoleLabelPrefix = "node-role.kubernetes.io/"
+
+// nodeRoleGroup returns the single role a node is counted under for
+// readiness thresholds: "control-plane" for master or control-plane nodes
+// (including compact clusters), otherwise its first role other than "worker"
+// (e.g. "infra" or a custom role), otherwise "worker", or "none" for a node
+// without role labels.
+func nodeRoleGroup(node corev1.Node) string {
+	roles := nodeRoles(node)
+	if slices.Contains(roles, "master") || slices.Contains(roles, "control-plane") {
+		return "control-plane"
+	}
+	for _, role := range roles {
+		if role != "worker" {
+			return role
+		}
+	}
+	if len(roles) > 0 {
+		return "worker"
+	}
+	return "none"
+}
+
+// nodeRoles returns the sorted roles of a node taken from its node-role.kubernetes.io/* labels.
+func nodeRoles(node corev1.Node) []string {
+	var roles []string
+	for label := range node.Labels {
+		if role, ok := strings.CutPrefix(label, nodeRoleLabelPrefix); ok && role != "" {
+			roles = append(roles, role)
+		}
+	}
+	sort.Strings(roles)
+	return roles
+}
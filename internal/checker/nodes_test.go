@@ -0,0 +1,196 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+func makeNode(name string, ready corev1.ConditionStatus, roles ...string) *corev1.Node {
+	labels := map[string]string{}
+	for _, role := range roles {
+		labels[nodeRoleLabelPrefix+role] = ""
+	}
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: ready}},
+		},
+	}
+}
+
+func TestNodeRoles(t *testing.T) {
+	node := makeNode("n1", corev1.ConditionTrue, "worker", "master")
+	node.Labels["kubernetes.io/hostname"] = "n1"
+
+	if got := nodeRoles(*node); !reflect.DeepEqual(got, []string{"master", "worker"}) {
+		t.Errorf("expected roles [master worker], got %v", got)
+	}
+}
+
+func TestNodesCheck_NotReady(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		makeNode("master-0", corev1.ConditionTrue, "master"),
+		makeNode("worker-0", corev1.ConditionFalse, "worker"),
+	)
+
+	result := NewNodesCheck(NewAPISource(client, nil, nil)).Run(context.Background(), config.Config{})
+	if result.Healthy() {
+		t.Fatal("expected nodes check to be unhealthy")
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "worker-0" {
+		t.Errorf("expected worker-0 to be the only affected node, got %v", result.Affected)
+	}
+
+	if got := testutil.ToFloat64(metrics.NodeReady.WithLabelValues("master-0", "master")); got != 1 {
+		t.Errorf("expected openshift_node_ready{node=master-0}=1, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.NodeReady.WithLabelValues("worker-0", "worker")); got != 0 {
+		t.Errorf("expected openshift_node_ready{node=worker-0}=0, got %v", got)
+	}
+}
+
+func TestNodesCheck_APIErrorIsUnknown(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("list", "nodes", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("connection refused")
+	})
+
+	result := NewNodesCheck(NewAPISource(client, nil, nil)).Run(context.Background(), config.Config{})
+	if result.Status != StatusUnknown {
+		t.Errorf("expected API error to produce an unknown result, got %q", result.Status)
+	}
+	if result.Err == nil {
+		t.Error("expected result error to be set")
+	}
+}
+
+func TestNodeProblemsCheck(t *testing.T) {
+	pressured := makeNode("worker-0", corev1.ConditionTrue, "worker")
+	pressured.Status.Conditions = append(pressured.Status.Conditions,
+		corev1.NodeCondition{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionTrue},
+		corev1.NodeCondition{Type: corev1.NodeDiskPressure, Status: corev1.ConditionFalse},
+	)
+	cordoned := makeNode("worker-1", corev1.ConditionTrue, "worker")
+	cordoned.Spec.Unschedulable = true
+	cordoned.Spec.Taints = []corev1.Taint{
+		{Key: "node.kubernetes.io/unschedulable", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "example.com/maintenance", Effect: corev1.TaintEffectNoSchedule},
+		{Key: "example.com/maintenance", Effect: corev1.TaintEffectNoExecute},
+	}
+	client := fake.NewSimpleClientset(makeNode("master-0", corev1.ConditionTrue, "master"), pressured, cordoned)
+	cfg := config.Config{NodeProblems: config.NodeProblemNames, NodeProblemTaints: []string{"example.com/maintenance"}}
+
+	result := NewNodeProblemsCheck(NewAPISource(client, nil, nil)).Run(context.Background(), cfg)
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	if len(result.Affected) != 2 {
+		t.Errorf("expected 2 affected nodes, got %v", result.Affected)
+	}
+
+	for _, labels := range [][]string{
+		{"worker-0", "MemoryPressure"},
+		{"worker-1", "Unschedulable"},
+		{"worker-1", "Taint:example.com/maintenance"},
+	} {
+		if got := testutil.ToFloat64(metrics.NodeProblem.WithLabelValues(labels...)); got != 1 {
+			t.Errorf("expected openshift_node_problem%v=1, got %v", labels, got)
+		}
+	}
+	if n := testutil.CollectAndCount(metrics.NodeProblem); n != 3 {
+		t.Errorf("expected 3 openshift_node_problem series, got %d", n)
+	}
+}
+
+func TestNodeProblems_OnlyConfigured(t *testing.T) {
+	node := makeNode("worker-0", corev1.ConditionTrue, "worker")
+	node.Spec.Unschedulable = true
+	node.Spec.Taints = []corev1.Taint{{Key: "example.com/maintenance", Effect: corev1.TaintEffectPreferNoSchedule}}
+	cfg := config.Config{NodeProblems: []string{"MemoryPressure"}, NodeProblemTaints: []string{"example.com/maintenance:NoSchedule"}}
+
+	if got := nodeProblems(*node, cfg); len(got) != 0 {
+		t.Errorf("expected no problems when Unschedulable and the taint effect are not configured, got %v", got)
+	}
+}
+
+func TestNodesCheck_ProblemsDoNotAffectReadiness(t *testing.T) {
+	node := makeNode("worker-0", corev1.ConditionTrue, "worker")
+	node.Spec.Unschedulable = true
+	client := fake.NewSimpleClientset(node)
+
+	result := NewNodesCheck(NewAPISource(client, nil, nil)).Run(context.Background(), config.Config{NodeProblems: config.NodeProblemNames})
+	if !result.Healthy() {
+		t.Errorf("expected a cordoned Ready node to leave the nodes check healthy, got %+v", result)
+	}
+}
+
+func TestNodeRoleGroup(t *testing.T) {
+	for _, tc := range []struct {
+		roles []string
+		want  string
+	}{
+		{[]string{"master"}, "control-plane"},
+		{[]string{"control-plane", "master"}, "control-plane"},
+		{[]string{"master", "worker"}, "control-plane"},
+		{[]string{"infra", "worker"}, "infra"},
+		{[]string{"gpu", "worker"}, "gpu"},
+		{[]string{"worker"}, "worker"},
+		{nil, "none"},
+	} {
+		if got := nodeRoleGroup(*makeNode("n", corev1.ConditionTrue, tc.roles...)); got != tc.want {
+			t.Errorf("roles %v: expected %q, got %q", tc.roles, tc.want, got)
+		}
+	}
+}
+
+func TestNodesCheck_RoleThresholds(t *testing.T) {
+	objects := []runtime.Object{
+		makeNode("master-0", corev1.ConditionTrue, "master"),
+		makeNode("infra-0", corev1.ConditionFalse, "infra", "worker"),
+	}
+	for _, name := range []string{"worker-0", "worker-1", "worker-2", "worker-3", "worker-4", "worker-5", "worker-6", "worker-7", "worker-8", "worker-9"} {
+		objects = append(objects, makeNode(name, corev1.ConditionTrue, "worker"))
+	}
+	objects = append(objects, makeNode("worker-10", corev1.ConditionFalse, "worker"))
+	client := fake.NewSimpleClientset(objects...)
+	check := NewNodesCheck(NewAPISource(client, nil, nil))
+
+	// 1 of 11 workers is within 10%; the infra node is not tolerated.
+	cfg := config.Config{NodeNotReadyThresholds: map[string]config.NodeThreshold{"worker": {Percent: 10, HasPercent: true}}}
+	result := check.Run(context.Background(), cfg)
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "infra-0" {
+		t.Errorf("expected infra-0 to be the only affected node, got %v", result.Affected)
+	}
+	if got := testutil.ToFloat64(metrics.NodeRoleNotReady.WithLabelValues("worker")); got != 0 {
+		t.Errorf("expected openshift_node_role_not_ready{role=worker}=0, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.NodeRoleNotReady.WithLabelValues("infra")); got != 1 {
+		t.Errorf("expected openshift_node_role_not_ready{role=infra}=1, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.NodeRoleNodes.WithLabelValues("worker", "total")); got != 11 {
+		t.Errorf("expected 11 worker nodes, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.NodeRoleNodes.WithLabelValues("worker", "not_ready")); got != 1 {
+		t.Errorf("expected 1 NotReady worker node, got %v", got)
+	}
+
+	cfg.NodeNotReadyThresholds["infra"] = config.NodeThreshold{Count: 1, HasCount: true}
+	if result := check.Run(context.Background(), cfg); !result.Healthy() {
+		t.Errorf("expected healthy with infra=1 and worker=10%%, got %+v", result)
+	}
+}
@@ -0,0 +1,151 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// operatorProgressingCheck reports whether any ClusterOperator has been
+// Progressing=True for too long (openshift_cluster_operators_progressing_stuck).
+type operatorProgressingCheck struct {
+	source Source
+
+	// stuck holds the openshift_cluster_operator_progressing_stuck_seconds series.
+	stuck *metrics.Series
+
+	now func() time.Time
+}
+
+// NewOperatorProgressingCheck returns the "operatorprogressing" check.
+func NewOperatorProgressingCheck(source Source) Check {
+	return &operatorProgressingCheck{
+		source: source,
+		stuck:  metrics.NewSeries(metrics.ClusterOperatorProgressingStuck),
+		now:    time.Now,
+	}
+}
+
+// Name implements Check.
+func (c *operatorProgressingCheck) Name() string { return "operatorprogressing" }
+
+// reset implements resetter.
+func (c *operatorProgressingCheck) reset() {
+	c.stuck.Reset()
+}
+
+// Run lists all ClusterOperators, including etcd, and reports every one that
+// has been Progressing=True for longer than cfg.OperatorProgressingMaxAgeFor,
+// measured from the condition's lastTransitionTime. Operators progress during
+// upgrades and reconfigurations, but one that never finishes hides a rollout
+// that is stuck. Stuck operators are published to
+// openshift_cluster_operator_progressing_stuck_seconds.
+//
+// On API error, the result is unknown.
+func (c *operatorProgressingCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+
+	operators, err := c.source.ListClusterOperators(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list ClusterOperators: %v — check %q status unknown", err, c.Name())
+		result.fail(fmt.Errorf("failed to list ClusterOperators: %w", err))
+		return result
+	}
+
+	now := c.now()
+	var samples []metrics.Sample
+	for _, op := range operators {
+		if clusterOperatorConditionStatus(*op, configv1.OperatorProgressing) != configv1.ConditionTrue {
+			continue
+		}
+		since := clusterOperatorConditionSince(*op, configv1.OperatorProgressing)
+		if since.IsZero() {
+			continue
+		}
+		age := now.Sub(since)
+		maxAge := cfg.OperatorProgressingMaxAgeFor(op.Name)
+		if age <= maxAge {
+			continue
+		}
+
+		message := fmt.Sprintf("ClusterOperator %q has been progressing for %s (more than %s)", op.Name, age.Truncate(time.Second), maxAge)
+		if reason := clusterOperatorConditionReason(*op, configv1.OperatorProgressing); reason != "" {
+			message += ": " + reason
+		}
+		log.Printf("WARNING: %s", message)
+		result.addFailure(ObjectRef{Kind: "ClusterOperator", Name: op.Name}, message)
+		samples = append(samples, metrics.Sample{Labels: []string{op.Name}, Value: age.Seconds()})
+	}
+	c.stuck.Update(samples)
+
+	return result
+}
+
+// upgradeableCheck reports whether any ClusterOperator blocks minor version
+// upgrades (openshift_cluster_upgrade_blocked).
+type upgradeableCheck struct {
+	source Source
+
+	// blocked holds the openshift_cluster_operator_upgrade_blocked series.
+	blocked *metrics.Series
+}
+
+// NewUpgradeableCheck returns the "upgradeable" check.
+func NewUpgradeableCheck(source Source) Check {
+	return &upgradeableCheck{source: source, blocked: metrics.NewSeries(metrics.ClusterOperatorUpgradeBlocked)}
+}
+
+// Name implements Check.
+func (c *upgradeableCheck) Name() string { return "upgradeable" }
+
+// reset implements resetter.
+func (c *upgradeableCheck) reset() {
+	c.blocked.Reset()
+}
+
+// Run lists all ClusterOperators and reports every one with
+// Upgradeable=False, which makes the cluster-version operator refuse the next
+// minor version upgrade. Each such operator is published to
+// openshift_cluster_operator_upgrade_blocked with the condition's reason and
+// message. An operator without an Upgradeable condition is upgradeable.
+//
+// On API error, the result is unknown.
+func (c *upgradeableCheck) Run(ctx context.Context, _ config.Config) Result {
+	result := newResult(c.Name())
+
+	operators, err := c.source.ListClusterOperators(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list ClusterOperators: %v — check %q status unknown", err, c.Name())
+		result.fail(fmt.Errorf("failed to list ClusterOperators: %w", err))
+		return result
+	}
+
+	var samples []metrics.Sample
+	for _, op := range operators {
+		if clusterOperatorConditionStatus(*op, configv1.OperatorUpgradeable) != configv1.ConditionFalse {
+			continue
+		}
+		reason := clusterOperatorConditionReason(*op, configv1.OperatorUpgradeable)
+		message := labelMessage(clusterOperatorConditionMessage(*op, configv1.OperatorUpgradeable))
+
+		detail := fmt.Sprintf("ClusterOperator %q blocks upgrades (Upgradeable=False)", op.Name)
+		if reason != "" {
+			detail += ": " + reason
+		}
+		if message != "" {
+			detail += ": " + message
+		}
+		log.Printf("WARNING: %s", detail)
+		result.addFailure(ObjectRef{Kind: "ClusterOperator", Name: op.Name}, detail)
+		samples = append(samples, metrics.Sample{Labels: []string{op.Name, reason, message}, Value: 1})
+	}
+	c.blocked.Update(samples)
+
+	return result
+}
@@ -0,0 +1,97 @@
+package checker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	configv1 "github.com/openshift/api/config/v1"
+	ocpfake "github.com/openshift/client-go/config/clientset/versioned/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// withCondition returns op with an additional condition.
+func withCondition(op *configv1.ClusterOperator, cond configv1.ClusterOperatorStatusCondition) *configv1.ClusterOperator {
+	op.Status.Conditions = append(op.Status.Conditions, cond)
+	return op
+}
+
+func TestOperatorProgressingCheck(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	progressing := func(name string, status configv1.ConditionStatus, age time.Duration) *configv1.ClusterOperator {
+		return withCondition(makeOperator(name, configv1.ConditionFalse, configv1.ConditionTrue), configv1.ClusterOperatorStatusCondition{
+			Type:               configv1.OperatorProgressing,
+			Status:             status,
+			Reason:             "RollingOut",
+			LastTransitionTime: metav1.NewTime(now.Add(-age)),
+		})
+	}
+	client := ocpfake.NewSimpleClientset(
+		progressing("ingress", configv1.ConditionTrue, 2*time.Hour),
+		progressing("dns", configv1.ConditionTrue, 10*time.Minute),
+		progressing("console", configv1.ConditionFalse, 5*time.Hour),
+		progressing("machine-config", configv1.ConditionTrue, 2*time.Hour),
+	)
+
+	check := NewOperatorProgressingCheck(NewAPISource(nil, client.ConfigV1(), nil)).(*operatorProgressingCheck)
+	check.now = func() time.Time { return now }
+	cfg := config.Config{
+		OperatorProgressingMaxAge:  time.Hour,
+		OperatorProgressingMaxAges: map[string]time.Duration{"machine-config": 3 * time.Hour},
+	}
+
+	result := check.Run(context.Background(), cfg)
+	if result.Healthy() {
+		t.Fatal("expected operatorprogressing check to be unhealthy")
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "ingress" {
+		t.Errorf("expected ingress to be the only affected operator, got %v", result.Affected)
+	}
+	if !strings.Contains(result.Reasons[0], "RollingOut") {
+		t.Errorf("expected the reason to include the condition reason, got %q", result.Reasons[0])
+	}
+	if got := testutil.ToFloat64(metrics.ClusterOperatorProgressingStuck.WithLabelValues("ingress")); got != 7200 {
+		t.Errorf("expected ingress progressing for 7200s, got %v", got)
+	}
+}
+
+func TestUpgradeableCheck(t *testing.T) {
+	blocked := withCondition(makeOperator("kube-storage-version-migrator", configv1.ConditionFalse, configv1.ConditionTrue), configv1.ClusterOperatorStatusCondition{
+		Type:    configv1.OperatorUpgradeable,
+		Status:  configv1.ConditionFalse,
+		Reason:  "AdminAckRequired",
+		Message: "Kubernetes 1.32 removes\nseveral APIs.",
+	})
+	upgradeable := withCondition(makeOperator("ingress", configv1.ConditionFalse, configv1.ConditionTrue), configv1.ClusterOperatorStatusCondition{
+		Type:   configv1.OperatorUpgradeable,
+		Status: configv1.ConditionTrue,
+	})
+	client := ocpfake.NewSimpleClientset(blocked, upgradeable, makeOperator("dns", configv1.ConditionFalse, configv1.ConditionTrue))
+
+	result := NewUpgradeableCheck(NewAPISource(nil, client.ConfigV1(), nil)).Run(context.Background(), config.Config{})
+	if result.Healthy() {
+		t.Fatal("expected upgradeable check to be unhealthy")
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "kube-storage-version-migrator" {
+		t.Errorf("expected kube-storage-version-migrator to be the only affected operator, got %v", result.Affected)
+	}
+	labels := []string{"kube-storage-version-migrator", "AdminAckRequired", "Kubernetes 1.32 removes several APIs."}
+	if got := testutil.ToFloat64(metrics.ClusterOperatorUpgradeBlocked.WithLabelValues(labels...)); got != 1 {
+		t.Errorf("expected upgrade blocked series %v=1, got %v", labels, got)
+	}
+}
+
+func TestLabelMessage(t *testing.T) {
+	if got := labelMessage("  two\n\tlines  "); got != "two lines" {
+		t.Errorf("expected whitespace to be collapsed, got %q", got)
+	}
+	long := labelMessage(strings.Repeat("ä", maxLabelMessageLength+10))
+	if n := len([]rune(long)); n != maxLabelMessageLength || !strings.HasSuffix(long, "…") {
+		t.Errorf("expected a message cut to %d runes ending in an ellipsis, got %d runes", maxLabelMessageLength, n)
+	}
+}
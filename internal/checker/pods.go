@@ -2,98 +2,311 @@ package checker
 
 import (
 	"context"
+	"fmt"
 	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/client-go/kubernetes"
+	"k8s.io/apimachinery/pkg/labels"
 
 	"github.com/openshift-cluster-check/health-checker/internal/config"
 	"github.com/openshift-cluster-check/health-checker/internal/metrics"
 )
 
-// fatalContainerReasons are container waiting/terminated reasons that indicate a fatal failure.
-var fatalContainerReasons = map[string]bool{
-	"CrashLoopBackOff": true,
-	"OOMKilled":        true,
-	"Error":            true,
+// podFrequentRestarts is the openshift_system_pod_failing reason of a pod
+// whose containers restart too often but have no fatal reason at poll time.
+const podFrequentRestarts = "FrequentRestarts"
+
+// systemPodsCheck reports whether any pod in a system namespace is failing
+// (openshift_system_pods_failing).
+type systemPodsCheck struct {
+	source Source
+
+	// failing holds the openshift_system_pod_failing series.
+	failing *metrics.Series
+
+	// restartSeries holds the openshift_system_container_restarts_in_window series.
+	restartSeries *metrics.Series
+
+	// restarts remembers container restart counts between cycles.
+	restarts *restartTracker
+
+	now func() time.Time
 }
 
-// CheckSystemPods lists pods in each system namespace and updates openshift_system_pods_failing:
-//   - 1 if any pod has phase=Failed or any container has a fatal reason (CrashLoopBackOff, OOMKilled, Error)
-//   - 0 if all system pods are healthy
+// NewSystemPodsCheck returns the "systempods" check.
+func NewSystemPodsCheck(source Source) Check {
+	return &systemPodsCheck{
+		source:        source,
+		failing:       metrics.NewSeries(metrics.SystemPodFailing),
+		restartSeries: metrics.NewSeries(metrics.SystemContainerRestarts),
+		restarts:      newRestartTracker(),
+		now:           time.Now,
+	}
+}
+
+// Name implements Check.
+func (c *systemPodsCheck) Name() string { return "systempods" }
+
+// reset implements resetter.
+func (c *systemPodsCheck) reset() {
+	c.failing.Reset()
+	c.restartSeries.Reset()
+	c.restarts.forgetExcept(nil)
+}
+
+// Run lists pods in each system namespace and reports every pod that has
+// phase=Failed or a container with a fatal reason (by default
+// CrashLoopBackOff, OOMKilled, Error), following the namespace's
+// cfg.PodRulesFor: excluded pods, and Failed Job pods if FailedJobPods is
+// "ignore", are skipped. So are Failed pods superseded by a later attempt of
+// the same work (see supersededPods): older installer-* and revision-pruner-*
+// pods on the same node, and Job pods whose Job, or a newer Job of the same
+// CronJob, has completed, or whose Job has started a newer pod since.
+// A pod is also failing if one of its containers restarted at least
+// cfg.RestartThreshold times within cfg.RestartWindow, even if it is Running
+// at poll time; restarts are counted from the restartCount seen in earlier
+// cycles, and such containers are published to
+// openshift_system_container_restarts_in_window.
+// Every failing pod is published to openshift_system_pod_failing. Pods that
+// have not started yet (Pending, ContainerCreating, ...) are left to the
+// stuckpods check.
 //
-// Pods are listed per namespace (not cluster-wide) to minimize API server load.
-// On API error for any namespace, the metric is set to 1 (fail-closed).
-func CheckSystemPods(ctx context.Context, client kubernetes.Interface, cfg config.Config) {
+// Pods are listed per namespace (not cluster-wide) to minimize API server load
+// in polling mode.
+// On API error for any namespace, the result is unknown.
+func (c *systemPodsCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+
 	// Collect all system namespaces by listing all namespaces and filtering
-	nsList, err := client.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	namespaces, err := c.source.ListNamespaces(ctx)
 	if err != nil {
-		log.Printf("WARNING: failed to list Namespaces: %v — setting metric to 1 (fail-closed)", err)
-		metrics.SystemPodsFailing.Set(1)
-		return
+		log.Printf("WARNING: failed to list Namespaces: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Namespaces: %w", err))
+		return result
 	}
 
-	failing := false
-	for _, ns := range nsList.Items {
+	now := c.now()
+	var samples, restartSamples []metrics.Sample
+	seen := make(map[string]bool)
+	for _, ns := range namespaces {
 		if !IsSystemNamespace(ns.Name, cfg) {
 			continue
 		}
 
-		pods, err := client.CoreV1().Pods(ns.Name).List(ctx, metav1.ListOptions{})
+		pods, err := c.source.ListPods(ctx, ns.Name)
+		if err != nil {
+			log.Printf("WARNING: failed to list Pods in namespace %q: %v — check status unknown", ns.Name, err)
+			result.fail(fmt.Errorf("failed to list Pods in namespace %q: %w", ns.Name, err))
+			return result
+		}
+
+		rules := cfg.PodRulesFor(ns.Name)
+		exclude, err := labels.Parse(rules.ExcludeSelector)
 		if err != nil {
-			log.Printf("WARNING: failed to list Pods in namespace %q: %v — setting metric to 1 (fail-closed)", ns.Name, err)
-			metrics.SystemPodsFailing.Set(1)
-			return
+			// Validated when the configuration is loaded.
+			log.Printf("WARNING: invalid pod exclude selector %q for namespace %q: %v — not excluding by label", rules.ExcludeSelector, ns.Name, err)
+			exclude = labels.Nothing()
+		}
+
+		var jobs []*batchv1.Job
+		if slices.ContainsFunc(pods, func(pod *corev1.Pod) bool { return ownedBy(*pod, "Job") }) {
+			jobs, err = c.source.ListJobs(ctx, ns.Name)
+			if err != nil {
+				log.Printf("WARNING: failed to list Jobs in namespace %q: %v — check status unknown", ns.Name, err)
+				result.fail(fmt.Errorf("failed to list Jobs in namespace %q: %w", ns.Name, err))
+				return result
+			}
 		}
+		superseded := supersededPods(pods, jobs)
 
-		for _, pod := range pods.Items {
-			if isPodFailing(pod) {
-				failing = true
-				log.Printf("WARNING: Pod %q in namespace %q is failing", pod.Name, pod.Namespace)
+		for _, pod := range pods {
+			if isPodSkipped(*pod, rules, exclude) {
+				continue
+			}
+			if superseded[pod.Name] && pod.Status.Phase == corev1.PodFailed {
+				continue
 			}
+
+			var restarting []string
+			for _, cs := range podContainerStatuses(*pod) {
+				key := restartKey(*pod, cs.Name)
+				seen[key] = true
+				n := c.restarts.observe(key, now, cs.RestartCount, cfg.RestartWindow)
+				if n < cfg.RestartThreshold {
+					continue
+				}
+				restartSamples = append(restartSamples, metrics.Sample{Labels: []string{pod.Namespace, pod.Name, cs.Name}, Value: float64(n)})
+				detail := fmt.Sprintf("container %q restarted %d times in %s", cs.Name, n, cfg.RestartWindow)
+				if t := cs.LastTerminationState.Terminated; t != nil && t.Reason != "" {
+					detail += fmt.Sprintf(" (last terminated: %s)", t.Reason)
+				}
+				restarting = append(restarting, detail)
+			}
+
+			reason := podFailureReason(*pod, rules.FatalReasons)
+			if reason == "" && len(restarting) > 0 {
+				reason = podFrequentRestarts
+			}
+			if reason == "" {
+				continue
+			}
+			message := fmt.Sprintf("Pod %q in namespace %q is failing: %s", pod.Name, pod.Namespace, reason)
+			if len(restarting) > 0 {
+				message += "; " + strings.Join(restarting, "; ")
+			}
+			log.Printf("WARNING: %s", message)
+			result.addFailure(ObjectRef{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}, message)
+			samples = append(samples, metrics.Sample{Labels: []string{pod.Namespace, pod.Name, reason}, Value: 1})
 		}
 	}
+	c.restarts.forgetExcept(seen)
+	c.failing.Update(samples)
+	c.restartSeries.Update(restartSamples)
+
+	return result
+}
+
+// isPodSkipped reports whether the systempods check ignores the pod under
+// rules: the pod matches exclude, has an owner of one of
+// rules.ExcludeOwnerKinds, or is a Failed Job pod and rules.FailedJobPods is
+// "ignore".
+func isPodSkipped(pod corev1.Pod, rules config.PodRules, exclude labels.Selector) bool {
+	if !exclude.Empty() && exclude.Matches(labels.Set(pod.Labels)) {
+		return true
+	}
+	for _, owner := range pod.OwnerReferences {
+		if slices.Contains(rules.ExcludeOwnerKinds, owner.Kind) {
+			return true
+		}
+	}
+	return pod.Status.Phase == corev1.PodFailed && rules.FailedJobPods == config.FailedJobPodsIgnore && ownedBy(pod, "Job")
+}
 
-	if failing {
-		metrics.SystemPodsFailing.Set(1)
-	} else {
-		metrics.SystemPodsFailing.Set(0)
+// ownedBy reports whether the pod has an owner of the given kind.
+func ownedBy(pod corev1.Pod, kind string) bool {
+	for _, owner := range pod.OwnerReferences {
+		if owner.Kind == kind {
+			return true
+		}
 	}
+	return false
 }
 
-// isPodFailing returns true if the pod has phase=Failed or any container
-// has a fatal waiting or terminated reason.
-func isPodFailing(pod corev1.Pod) bool {
+// podFailureReason returns why the pod is failing, or empty string if it is not:
+// "Failed" for phase=Failed, otherwise the first fatal container reason found.
+func podFailureReason(pod corev1.Pod, fatalReasons []string) string {
 	// Check pod phase
 	if pod.Status.Phase == corev1.PodFailed {
-		return true
+		return string(corev1.PodFailed)
 	}
 
 	// Check container statuses for fatal states
 	for _, cs := range pod.Status.ContainerStatuses {
-		if isContainerFailing(cs) {
-			return true
+		if reason := containerFailureReason(cs, fatalReasons); reason != "" {
+			return reason
 		}
 	}
 
 	// Check init container statuses
 	for _, cs := range pod.Status.InitContainerStatuses {
-		if isContainerFailing(cs) {
-			return true
+		if reason := containerFailureReason(cs, fatalReasons); reason != "" {
+			return reason
 		}
 	}
 
-	return false
+	return ""
 }
 
-// isContainerFailing returns true if the container has a fatal waiting or terminated reason.
-func isContainerFailing(cs corev1.ContainerStatus) bool {
-	if cs.State.Waiting != nil && fatalContainerReasons[cs.State.Waiting.Reason] {
-		return true
+// containerFailureReason returns the container's fatal waiting or terminated reason,
+// or empty string if it has none.
+func containerFailureReason(cs corev1.ContainerStatus, fatalReasons []string) string {
+	if cs.State.Waiting != nil && slices.Contains(fatalReasons, cs.State.Waiting.Reason) {
+		return cs.State.Waiting.Reason
 	}
-	if cs.State.Terminated != nil && fatalContainerReasons[cs.State.Terminated.Reason] {
-		return true
+	if cs.State.Terminated != nil && slices.Contains(fatalReasons, cs.State.Terminated.Reason) {
+		return cs.State.Terminated.Reason
+	}
+	return ""
+}
+
+// podContainerStatuses returns the statuses of the pod's init and regular containers.
+func podContainerStatuses(pod corev1.Pod) []corev1.ContainerStatus {
+	statuses := make([]corev1.ContainerStatus, 0, len(pod.Status.InitContainerStatuses)+len(pod.Status.ContainerStatuses))
+	statuses = append(statuses, pod.Status.InitContainerStatuses...)
+	return append(statuses, pod.Status.ContainerStatuses...)
+}
+
+// restartKey identifies a container across cycles. The pod UID distinguishes
+// a recreated pod with the same name, whose restart count starts over.
+func restartKey(pod corev1.Pod, container string) string {
+	return string(pod.UID) + "/" + pod.Namespace + "/" + pod.Name + "/" + container
+}
+
+// restartSample is a container's restart count, first seen at the given time.
+type restartSample struct {
+	at    time.Time
+	count int32
+}
+
+// restartTracker remembers the restart counts of containers across cycles, so
+// restarts can be counted over a window even if the container is running
+// whenever it is polled. Only changes of the count are recorded, so a
+// container that does not restart costs a single sample however often it is
+// polled.
+type restartTracker struct {
+	mu      sync.Mutex
+	history map[string][]restartSample
+}
+
+func newRestartTracker() *restartTracker {
+	return &restartTracker{history: make(map[string][]restartSample)}
+}
+
+// observe records the container's current restart count and returns the
+// number of restarts within window before now: the current count minus the
+// count at the start of the window. Until the tracker has seen the container
+// for a whole window, only the restarts since it was first seen are counted.
+func (t *restartTracker) observe(key string, now time.Time, count int32, window time.Duration) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	samples := t.history[key]
+	// A lower count means the counter was reset; older samples are meaningless.
+	if n := len(samples); n > 0 && samples[n-1].count > count {
+		samples = nil
+	}
+
+	// Drop the samples that were superseded before the window started; the
+	// newest of them is the count at the start of the window.
+	cutoff := now.Add(-window)
+	first := 0
+	for first+1 < len(samples) && !samples[first+1].at.After(cutoff) {
+		first++
+	}
+	samples = samples[first:]
+
+	if n := len(samples); n == 0 || samples[n-1].count != count {
+		samples = append(samples, restartSample{at: now, count: count})
+	}
+	t.history[key] = samples
+
+	return int(count - samples[0].count)
+}
+
+// forgetExcept drops the history of every container not in seen, such as
+// containers of deleted pods.
+func (t *restartTracker) forgetExcept(seen map[string]bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for key := range t.history {
+		if !seen[key] {
+			delete(t.history, key)
+		}
 	}
-	return false
 }
@@ -1,10 +1,19 @@
 package checker
 
 import (
+	"context"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
 )
 
 func makePod(name, namespace string, phase corev1.PodPhase, containerStatuses []corev1.ContainerStatus) corev1.Pod {
@@ -22,7 +31,7 @@ func makePod(name, namespace string, phase corev1.PodPhase, containerStatuses []
 
 func TestIsPodFailing_PhaseFailed(t *testing.T) {
 	pod := makePod("test-pod", "openshift-monitoring", corev1.PodFailed, nil)
-	if !isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) == "" {
 		t.Error("expected pod with phase=Failed to be failing")
 	}
 }
@@ -37,7 +46,7 @@ func TestIsPodFailing_PhaseRunning_Healthy(t *testing.T) {
 			},
 		},
 	})
-	if isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) != "" {
 		t.Error("expected running healthy pod to NOT be failing")
 	}
 }
@@ -54,7 +63,7 @@ func TestIsPodFailing_CrashLoopBackOff(t *testing.T) {
 			},
 		},
 	})
-	if !isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) == "" {
 		t.Error("expected pod with CrashLoopBackOff container to be failing")
 	}
 }
@@ -71,7 +80,7 @@ func TestIsPodFailing_OOMKilled(t *testing.T) {
 			},
 		},
 	})
-	if !isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) == "" {
 		t.Error("expected pod with OOMKilled container to be failing")
 	}
 }
@@ -88,21 +97,21 @@ func TestIsPodFailing_ErrorTerminated(t *testing.T) {
 			},
 		},
 	})
-	if !isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) == "" {
 		t.Error("expected pod with Error terminated container to be failing")
 	}
 }
 
 func TestIsPodFailing_PhaseSucceeded(t *testing.T) {
 	pod := makePod("completed-pod", "openshift-monitoring", corev1.PodSucceeded, nil)
-	if isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) != "" {
 		t.Error("expected pod with phase=Succeeded to NOT be failing")
 	}
 }
 
 func TestIsPodFailing_PhasePending(t *testing.T) {
 	pod := makePod("pending-pod", "openshift-monitoring", corev1.PodPending, nil)
-	if isPodFailing(pod) {
+	if podFailureReason(pod, config.DefaultFatalContainerReasons) != "" {
 		t.Error("expected pod with phase=Pending to NOT be failing")
 	}
 }
@@ -113,7 +122,7 @@ func TestIsContainerFailing_WaitingCrashLoopBackOff(t *testing.T) {
 			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
 		},
 	}
-	if !isContainerFailing(cs) {
+	if containerFailureReason(cs, config.DefaultFatalContainerReasons) == "" {
 		t.Error("expected CrashLoopBackOff waiting container to be failing")
 	}
 }
@@ -124,7 +133,7 @@ func TestIsContainerFailing_WaitingOtherReason(t *testing.T) {
 			Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"},
 		},
 	}
-	if isContainerFailing(cs) {
+	if containerFailureReason(cs, config.DefaultFatalContainerReasons) != "" {
 		t.Error("expected ContainerCreating waiting container to NOT be failing")
 	}
 }
@@ -135,7 +144,7 @@ func TestIsContainerFailing_TerminatedOOMKilled(t *testing.T) {
 			Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
 		},
 	}
-	if !isContainerFailing(cs) {
+	if containerFailureReason(cs, config.DefaultFatalContainerReasons) == "" {
 		t.Error("expected OOMKilled terminated container to be failing")
 	}
 }
@@ -146,7 +155,191 @@ func TestIsContainerFailing_TerminatedCompleted(t *testing.T) {
 			Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"},
 		},
 	}
-	if isContainerFailing(cs) {
+	if containerFailureReason(cs, config.DefaultFatalContainerReasons) != "" {
 		t.Error("expected Completed terminated container to NOT be failing")
 	}
 }
+
+func TestPodFailureReason(t *testing.T) {
+	pod := makePod("oom-pod", "openshift-monitoring", corev1.PodRunning, []corev1.ContainerStatus{
+		{
+			Name: "app",
+			State: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+			},
+		},
+	})
+	if got := podFailureReason(pod, config.DefaultFatalContainerReasons); got != "OOMKilled" {
+		t.Errorf("expected reason OOMKilled, got %q", got)
+	}
+
+	failed := makePod("failed-pod", "openshift-monitoring", corev1.PodFailed, nil)
+	if got := podFailureReason(failed, config.DefaultFatalContainerReasons); got != "Failed" {
+		t.Errorf("expected reason Failed, got %q", got)
+	}
+
+	healthy := makePod("ok-pod", "openshift-monitoring", corev1.PodRunning, nil)
+	if got := podFailureReason(healthy, config.DefaultFatalContainerReasons); got != "" {
+		t.Errorf("expected no reason for healthy pod, got %q", got)
+	}
+}
+
+func TestContainerFailureReason(t *testing.T) {
+	waiting := corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		},
+	}
+	if got := containerFailureReason(waiting, config.DefaultFatalContainerReasons); got != "CrashLoopBackOff" {
+		t.Errorf("expected reason CrashLoopBackOff, got %q", got)
+	}
+
+	terminated := corev1.ContainerStatus{
+		State: corev1.ContainerState{
+			Terminated: &corev1.ContainerStateTerminated{Reason: "Error"},
+		},
+	}
+	if got := containerFailureReason(terminated, config.DefaultFatalContainerReasons); got != "Error" {
+		t.Errorf("expected reason Error, got %q", got)
+	}
+	if got := containerFailureReason(terminated, []string{"OOMKilled"}); got != "" {
+		t.Errorf("expected no reason for a reason that is not fatal, got %q", got)
+	}
+}
+
+func TestRestartTracker(t *testing.T) {
+	tracker := newRestartTracker()
+	start := time.Now()
+
+	if n := tracker.observe("c", start, 10, time.Hour); n != 0 {
+		t.Errorf("expected no restarts on first observation, got %d", n)
+	}
+	if n := tracker.observe("c", start.Add(30*time.Minute), 13, time.Hour); n != 3 {
+		t.Errorf("expected 3 restarts, got %d", n)
+	}
+	// The count was 10 when the window started.
+	if n := tracker.observe("c", start.Add(70*time.Minute), 14, time.Hour); n != 4 {
+		t.Errorf("expected 4 restarts within the window, got %d", n)
+	}
+	// The count was 13 when the window started.
+	if n := tracker.observe("c", start.Add(95*time.Minute), 14, time.Hour); n != 1 {
+		t.Errorf("expected 1 restart within the window, got %d", n)
+	}
+	// A lower count means the counter was reset.
+	if n := tracker.observe("c", start.Add(96*time.Minute), 0, time.Hour); n != 0 {
+		t.Errorf("expected no restarts after a reset, got %d", n)
+	}
+
+	tracker.forgetExcept(map[string]bool{})
+	if len(tracker.history) != 0 {
+		t.Errorf("expected history of unseen containers to be dropped, got %v", tracker.history)
+	}
+}
+
+func TestRestartTracker_BoundedHistory(t *testing.T) {
+	tracker := newRestartTracker()
+	start := time.Now()
+
+	// An hour of 5s cycles with a stable count keeps a single sample.
+	for i := 0; i < 720; i++ {
+		tracker.observe("c", start.Add(time.Duration(i)*5*time.Second), 3, time.Hour)
+	}
+	if got := len(tracker.history["c"]); got != 1 {
+		t.Errorf("expected 1 sample for a stable restart count, got %d", got)
+	}
+
+	// Changes are recorded, and dropped once they are older than the window.
+	tracker.observe("c", start.Add(time.Hour), 4, time.Hour)
+	tracker.observe("c", start.Add(time.Hour+time.Minute), 5, time.Hour)
+	if got := len(tracker.history["c"]); got != 3 {
+		t.Errorf("expected 3 samples after two restarts, got %d", got)
+	}
+	if n := tracker.observe("c", start.Add(3*time.Hour), 5, time.Hour); n != 0 {
+		t.Errorf("expected no restarts within the window, got %d", n)
+	}
+	if got := len(tracker.history["c"]); got != 1 {
+		t.Errorf("expected the samples before the window to be dropped, got %d", got)
+	}
+}
+
+func TestSystemPodsCheck_FrequentRestarts(t *testing.T) {
+	pod := makePod("router", "openshift-ingress", corev1.PodRunning, []corev1.ContainerStatus{{
+		Name:                 "router",
+		RestartCount:         2,
+		State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"}},
+	}})
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}}, &pod)
+	check := NewSystemPodsCheck(NewAPISource(client, nil, nil)).(*systemPodsCheck)
+	now := time.Now()
+	check.now = func() time.Time { return now }
+	cfg := config.Config{SystemNamespacePrefixes: []string{"openshift-"}, RestartWindow: time.Hour, RestartThreshold: 3, PodRules: config.PodRules{FatalReasons: config.DefaultFatalContainerReasons}}
+
+	if result := check.Run(context.Background(), cfg); !result.Healthy() {
+		t.Fatalf("expected healthy on the first cycle, got %+v", result)
+	}
+
+	pod.Status.ContainerStatuses[0].RestartCount = 5
+	if _, err := client.CoreV1().Pods(pod.Namespace).UpdateStatus(context.Background(), &pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("failed to update pod: %v", err)
+	}
+	now = now.Add(10 * time.Minute)
+
+	result := check.Run(context.Background(), cfg)
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected 3 restarts in the window to be unhealthy, got %+v", result)
+	}
+	if len(result.Reasons) != 1 || !strings.Contains(result.Reasons[0], "OOMKilled") {
+		t.Errorf("expected the last termination reason in the result, got %v", result.Reasons)
+	}
+	if got := testutil.ToFloat64(metrics.SystemPodFailing.WithLabelValues("openshift-ingress", "router", podFrequentRestarts)); got != 1 {
+		t.Errorf("expected openshift_system_pod_failing with reason %s, got %v", podFrequentRestarts, got)
+	}
+	if got := testutil.ToFloat64(metrics.SystemContainerRestarts.WithLabelValues("openshift-ingress", "router", "router")); got != 3 {
+		t.Errorf("expected openshift_system_container_restarts_in_window=3, got %v", got)
+	}
+}
+
+func TestSystemPodsCheck_PodRules(t *testing.T) {
+	jobOwner := []metav1.OwnerReference{{Kind: "Job", Name: "collect-profiles-28000000"}}
+	failedJobPod := makePod("collect-profiles-28000000-abcde", "openshift-operator-lifecycle-manager", corev1.PodFailed, nil)
+	failedJobPod.OwnerReferences = jobOwner
+	installer := makePod("installer-7-master-0", "openshift-kube-apiserver", corev1.PodFailed, nil)
+	installer.Labels = map[string]string{"app": "installer"}
+	otherFailed := makePod("installer-3-master-0", "openshift-etcd", corev1.PodFailed, nil)
+	otherFailed.Labels = map[string]string{"app": "installer"}
+	configError := makePod("console-abcde", "openshift-console", corev1.PodRunning, []corev1.ContainerStatus{
+		{Name: "console", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CreateContainerError"}}},
+	})
+
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-operator-lifecycle-manager"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-kube-apiserver"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-etcd"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-console"}},
+		&failedJobPod, &installer, &otherFailed, &configError,
+	)
+	selector := "app=installer"
+	cfg := config.Config{
+		SystemNamespacePrefixes: []string{"openshift-"},
+		RestartWindow:           time.Hour,
+		RestartThreshold:        5,
+		PodRules: config.PodRules{
+			FatalReasons:  []string{"CrashLoopBackOff", "OOMKilled", "Error", "CreateContainerError"},
+			FailedJobPods: config.FailedJobPodsIgnore,
+		},
+		NamespacePodRules: []config.NamespacePodRules{
+			{Namespaces: []string{"openshift-kube-*"}, ExcludeSelector: &selector},
+		},
+	}
+
+	result := NewSystemPodsCheck(NewAPISource(client, nil, nil)).Run(context.Background(), cfg)
+	got := map[string]bool{}
+	for _, ref := range result.Affected {
+		got[ref.Name] = true
+	}
+	want := map[string]bool{"installer-3-master-0": true, "console-abcde": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected affected pods %v, got %v", want, got)
+	}
+}
@@ -0,0 +1,371 @@
+package checker
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// entry is a registered check together with the binary gauge it drives.
+type entry struct {
+	check   Check
+	gauge   prometheus.Gauge
+	enabled bool
+
+	// inflight is true while a run of the check is in progress, including a
+	// run that has outlived its deadline.
+	inflight atomic.Bool
+
+	// The fields below are only accessed by publish, which runs once per
+	// check per cycle, and by reset, between cycles (from Reset or
+	// SetDisabled); cycles never overlap.
+
+	// unhealthy is the state the gauge reports once hysteresis is applied.
+	unhealthy bool
+
+	// streak counts the consecutive healthy or unhealthy results that
+	// disagree with unhealthy.
+	streak int
+
+	// observed and lastUnhealthy record the latest healthy or unhealthy
+	// result, to count flaps.
+	observed      bool
+	lastUnhealthy bool
+}
+
+// resetter is implemented by checks that publish labelled series or keep
+// state between runs, so that it can be cleared when the check stops running.
+type resetter interface {
+	// reset deletes the check's series and forgets its state.
+	reset()
+}
+
+// reset clears everything published for the check, for when it stops
+// running (it is disabled, or this replica loses leadership): the binary
+// gauge is set to 0, the check's health_checker_* series and labelled series
+// are deleted, and hysteresis starts afresh.
+func (e *entry) reset() {
+	name := e.check.Name()
+	if e.gauge != nil {
+		e.gauge.Set(0)
+	}
+	metrics.CheckDuration.DeletePartialMatch(prometheus.Labels{"check": name})
+	metrics.CheckErrors.DeletePartialMatch(prometheus.Labels{"check": name})
+	metrics.CheckLastSuccess.DeleteLabelValues(name)
+	metrics.CheckUnknown.DeleteLabelValues(name)
+	metrics.CheckRawUnhealthy.DeleteLabelValues(name)
+	metrics.CheckFlaps.DeleteLabelValues(name)
+	if r, ok := e.check.(resetter); ok {
+		r.reset()
+	}
+	e.unhealthy, e.streak = false, 0
+	e.observed, e.lastUnhealthy = false, false
+}
+
+// publish sets the entry's binary gauge from the result: 0 = healthy, 1 = unhealthy.
+// The gauge only changes after cfg.HysteresisFor(check) consecutive results
+// of the other state; these are counted per cycle, and in watch mode cycles
+// also run on changes, more often than every CheckInterval.
+// health_checker_check_raw_unhealthy follows every healthy or unhealthy
+// result, and health_checker_check_flaps_total counts when it changes.
+// For an unknown result the gauge keeps its last value (config.UnknownGaugeHold)
+// or is set to 1 (config.UnknownGaugeFailClosed, the pre-tri-state behaviour);
+// either way health_checker_check_unknown is set for the check.
+// Checks registered without a gauge only update the health_checker_check_* metrics.
+func (e *entry) publish(result Result, cfg config.Config) {
+	unknown := result.Status == StatusUnknown
+	metrics.CheckUnknown.WithLabelValues(result.Check).Set(boolToFloat(unknown))
+
+	if unknown {
+		if e.gauge != nil && cfg.UnknownGaugeMode == config.UnknownGaugeFailClosed {
+			e.gauge.Set(1)
+		}
+		// Hold the last known value; the unknown metric carries the signal.
+		return
+	}
+
+	unhealthy := !result.Healthy()
+	metrics.CheckRawUnhealthy.WithLabelValues(result.Check).Set(boolToFloat(unhealthy))
+	if e.observed && unhealthy != e.lastUnhealthy {
+		metrics.CheckFlaps.WithLabelValues(result.Check).Inc()
+	}
+	e.observed, e.lastUnhealthy = true, unhealthy
+
+	if unhealthy == e.unhealthy {
+		e.streak = 0
+	} else {
+		e.streak++
+		h := cfg.HysteresisFor(result.Check)
+		required := h.HealthyCycles
+		if unhealthy {
+			required = h.UnhealthyCycles
+		}
+		if e.streak >= required {
+			e.unhealthy, e.streak = unhealthy, 0
+		}
+	}
+
+	if e.gauge != nil {
+		e.gauge.Set(boolToFloat(e.unhealthy))
+	}
+}
+
+// Registry holds the set of known checks in registration order and tracks
+// which of them are enabled. Checks are enabled when registered.
+type Registry struct {
+	entries []*entry
+	byName  map[string]*entry
+
+	// source is what the checks of user-defined operator groups read from
+	// (see SetOperatorGroups); nil unless built by NewDefaultRegistry.
+	source Source
+
+	// groups are the user-defined operator groups that have a registered
+	// check, keyed by check name.
+	groups map[string]config.OperatorGroup
+
+	// cycle is held for the duration of RunChecks to prevent overlapping cycles.
+	cycle sync.Mutex
+
+	// latest is the outcome of the most recent completed cycle, nil before
+	// the first one.
+	latest atomic.Pointer[Snapshot]
+}
+
+// Snapshot is the outcome of one completed check cycle.
+type Snapshot struct {
+	// Time is when the cycle completed.
+	Time time.Time `json:"time"`
+
+	// Status is the overall status of the cycle (see OverallStatus).
+	Status Status `json:"status"`
+
+	// Checks are the results of the enabled checks, in registration order.
+	Checks []Result `json:"checks"`
+}
+
+// Latest returns the outcome of the most recent completed check cycle, and
+// false if no cycle has completed yet. The returned results are shared and
+// must not be modified.
+func (r *Registry) Latest() (Snapshot, bool) {
+	s := r.latest.Load()
+	if s == nil {
+		return Snapshot{}, false
+	}
+	return *s, true
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{byName: map[string]*entry{}, groups: map[string]config.OperatorGroup{}}
+}
+
+// NewDefaultRegistry returns a Registry containing every built-in check, all
+// reading from source. New built-in checks are added here; RunChecks and main
+// do not need to change. The checks of user-defined operator groups are added
+// by SetOperatorGroups.
+func NewDefaultRegistry(source Source) *Registry {
+	reg := NewRegistry()
+	reg.source = source
+	reg.MustRegister(NewClusterOperatorsCheck(source), metrics.ClusterOperatorsDegraded)
+	reg.MustRegister(NewEtcdCheck(source), metrics.EtcdDegraded)
+	reg.MustRegister(NewOperatorProgressingCheck(source), metrics.ClusterOperatorsProgressingStuck)
+	reg.MustRegister(NewUpgradeableCheck(source), metrics.ClusterUpgradeBlocked)
+	reg.MustRegister(NewClusterVersionCheck(source), metrics.ClusterVersionDegraded)
+	reg.MustRegister(NewNodesCheck(source), metrics.NodesNotReady)
+	reg.MustRegister(NewNodeProblemsCheck(source), metrics.NodesImpaired)
+	reg.MustRegister(NewMachineConfigPoolsCheck(source), metrics.MachineConfigPoolsDegraded)
+	reg.MustRegister(NewSystemPodsCheck(source), metrics.SystemPodsFailing)
+	reg.MustRegister(NewStuckPodsCheck(source), metrics.SystemPodsStuck)
+	reg.MustRegister(NewWorkloadsCheck(source), metrics.SystemWorkloadsUnavailable)
+	reg.MustRegister(NewCertificatesCheck(source), metrics.CertificatesExpiring)
+	reg.MustRegister(NewCSRsCheck(source), metrics.CSRPendingTooLong)
+	return reg
+}
+
+// Reset clears everything published for every registered check (see
+// entry.reset) and forgets the latest Snapshot. It is called when this
+// replica stops running checks, so it does not keep exporting stale results.
+// It waits for a cycle in progress to complete.
+func (r *Registry) Reset() {
+	r.cycle.Lock()
+	defer r.cycle.Unlock()
+
+	for _, e := range r.entries {
+		e.reset()
+	}
+	r.latest.Store(nil)
+}
+
+// Register adds a check to the registry. The gauge, if non-nil, is set from
+// every result the check returns. Returns an error if a check with the same
+// name is already registered.
+func (r *Registry) Register(c Check, gauge prometheus.Gauge) error {
+	name := c.Name()
+	if _, ok := r.byName[name]; ok {
+		return fmt.Errorf("check %q is already registered", name)
+	}
+	e := &entry{check: c, gauge: gauge, enabled: true}
+	r.entries = append(r.entries, e)
+	r.byName[name] = e
+	return nil
+}
+
+// MustRegister is like Register but panics on error.
+func (r *Registry) MustRegister(c Check, gauge prometheus.Gauge) {
+	if err := r.Register(c, gauge); err != nil {
+		panic(err)
+	}
+}
+
+// SetOperatorGroups registers a check for every user-defined operator group
+// (see NewOperatorGroupCheck), driving the group's
+// openshift_cluster_operator_group_degraded series, and unregisters the checks
+// of groups that are no longer defined, deleting their series. A group whose
+// severity changed is registered afresh, as its gauge is a different series.
+// Like SetDisabled, it must be called between cycles, and before SetDisabled
+// so that group checks can be disabled.
+func (r *Registry) SetOperatorGroups(groups []config.OperatorGroup) {
+	defined := make(map[string]config.OperatorGroup, len(groups))
+	for _, g := range groups {
+		defined[operatorGroupCheckName(g.Name)] = g
+	}
+
+	for name, old := range r.groups {
+		if g, ok := defined[name]; ok && g.Severity == old.Severity {
+			continue
+		}
+		r.unregister(name)
+		metrics.ClusterOperatorGroupDegraded.DeleteLabelValues(old.Name, old.Severity)
+		delete(r.groups, name)
+	}
+	for _, g := range groups {
+		name := operatorGroupCheckName(g.Name)
+		if _, ok := r.groups[name]; ok {
+			continue
+		}
+		r.MustRegister(NewOperatorGroupCheck(r.source, g.Name), metrics.ClusterOperatorGroupDegraded.WithLabelValues(g.Name, g.Severity))
+		r.groups[name] = g
+	}
+}
+
+// unregister resets the named check (see entry.reset) and removes it from the
+// registry.
+func (r *Registry) unregister(name string) {
+	e, ok := r.byName[name]
+	if !ok {
+		return
+	}
+	e.reset()
+	r.entries = slices.DeleteFunc(r.entries, func(other *entry) bool { return other == e })
+	delete(r.byName, name)
+}
+
+// SetDisabled enables every registered check except the named ones. A check
+// that was enabled and is now disabled is reset (see entry.reset), so its
+// gauges and series do not keep reporting a result it no longer checks.
+// Returns an error naming any check that is not registered, so that typos in
+// configuration are caught at startup rather than silently ignored.
+func (r *Registry) SetDisabled(names []string) error {
+	var unknown []string
+	disabled := map[string]bool{}
+	for _, name := range names {
+		if _, ok := r.byName[name]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		disabled[name] = true
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("unknown check(s) %s (known: %s)", strings.Join(unknown, ", "), strings.Join(r.Names(), ", "))
+	}
+
+	for _, e := range r.entries {
+		enabled := !disabled[e.check.Name()]
+		if e.enabled && !enabled {
+			e.reset()
+		}
+		e.enabled = enabled
+	}
+	return nil
+}
+
+// Validate returns an error naming any check referenced by cfg (in
+// DisabledChecks, CheckTimeouts or CheckHysteresis) that is not registered.
+// The checks of the operator groups defined in cfg count as registered, and
+// those of groups it no longer defines do not, as SetOperatorGroups applies
+// cfg.OperatorGroups along with the rest of cfg.
+func (r *Registry) Validate(cfg config.Config) error {
+	known := map[string]bool{}
+	for name := range r.byName {
+		if _, ok := r.groups[name]; !ok {
+			known[name] = true
+		}
+	}
+	for _, g := range cfg.OperatorGroups {
+		known[operatorGroupCheckName(g.Name)] = true
+	}
+
+	var unknown []string
+	for _, name := range cfg.DisabledChecks {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range cfg.CheckTimeouts {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	for name := range cfg.CheckHysteresis {
+		if !known[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		names := slices.Sorted(maps.Keys(known))
+		return fmt.Errorf("unknown check(s) %s (known: %s)", strings.Join(unknown, ", "), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// Names returns the names of all registered checks, sorted.
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.entries))
+	for _, e := range r.entries {
+		names = append(names, e.check.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Enabled returns the enabled checks in registration order.
+func (r *Registry) Enabled() []Check {
+	var checks []Check
+	for _, e := range r.enabled() {
+		checks = append(checks, e.check)
+	}
+	return checks
+}
+
+// enabled returns the enabled entries in registration order.
+func (r *Registry) enabled() []*entry {
+	var entries []*entry
+	for _, e := range r.entries {
+		if e.enabled {
+			entries = append(entries, e)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,314 @@
+package checker
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// staticCheck is a Check that always returns the same status.
+type staticCheck struct {
+	name   string
+	status Status
+	runs   int
+}
+
+func (c *staticCheck) Name() string { return c.name }
+
+func (c *staticCheck) Run(_ context.Context, _ config.Config) Result {
+	c.runs++
+	return Result{Check: c.name, Status: c.status}
+}
+
+// seriesCheck is an unhealthy Check that publishes one labelled series.
+type seriesCheck struct {
+	name   string
+	vec    *prometheus.GaugeVec
+	series *metrics.Series
+}
+
+func newSeriesCheck(name string) *seriesCheck {
+	vec := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name + "_series", Help: name}, []string{"object"})
+	return &seriesCheck{name: name, vec: vec, series: metrics.NewSeries(vec)}
+}
+
+func (c *seriesCheck) Name() string { return c.name }
+
+func (c *seriesCheck) Run(_ context.Context, _ config.Config) Result {
+	c.series.Update([]metrics.Sample{{Labels: []string{"object-1"}, Value: 1}})
+	result := newResult(c.name)
+	result.addFailure(ObjectRef{Kind: "Test", Name: "object-1"}, "object-1 is broken")
+	return result
+}
+
+func (c *seriesCheck) reset() { c.series.Reset() }
+
+func newTestGauge(name string) prometheus.Gauge {
+	return prometheus.NewGauge(prometheus.GaugeOpts{Name: name, Help: name})
+}
+
+func TestRegistry_RegisterDuplicate(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.Register(&staticCheck{name: "a"}, nil); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := reg.Register(&staticCheck{name: "a"}, nil); err == nil {
+		t.Fatal("expected error registering duplicate check name, got nil")
+	}
+}
+
+func TestRegistry_SetDisabled(t *testing.T) {
+	reg := NewRegistry()
+	a := &staticCheck{name: "a", status: StatusHealthy}
+	b := &staticCheck{name: "b", status: StatusHealthy}
+	reg.MustRegister(a, nil)
+	reg.MustRegister(b, nil)
+
+	if err := reg.SetDisabled([]string{"b"}); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	results := RunChecks(context.Background(), reg, config.Config{})
+	if len(results) != 1 || results[0].Check != "a" {
+		t.Fatalf("expected only check a to run, got %v", results)
+	}
+	if b.runs != 0 {
+		t.Errorf("expected disabled check b not to run, ran %d times", b.runs)
+	}
+}
+
+func TestRegistry_SetDisabledUnknown(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(&staticCheck{name: "a"}, nil)
+	if err := reg.SetDisabled([]string{"typo"}); err == nil {
+		t.Fatal("expected error for unknown check name, got nil")
+	}
+}
+
+func TestRegistry_Validate(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(&staticCheck{name: "a"}, nil)
+
+	if err := reg.Validate(config.Config{DisabledChecks: []string{"a"}, CheckTimeouts: map[string]time.Duration{"a": time.Second}}); err != nil {
+		t.Errorf("expected no error for registered names, got: %v", err)
+	}
+	if err := reg.Validate(config.Config{CheckTimeouts: map[string]time.Duration{"typo": time.Second}}); err == nil {
+		t.Error("expected error for unknown check in CheckTimeouts, got nil")
+	}
+	if err := reg.Validate(config.Config{CheckHysteresis: map[string]config.Hysteresis{"typo": {UnhealthyCycles: 1, HealthyCycles: 1}}}); err == nil {
+		t.Error("expected error for unknown check in CheckHysteresis, got nil")
+	}
+}
+
+func TestReloadConfig_AppliesDisabledChecks(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("checkInterval: 30"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	// This registry has no certificates check to leave disabled.
+	t.Setenv("CERT_CHECK", "true")
+	cfg, err := config.Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	reloader := config.NewReloader(cfg)
+
+	reg := NewRegistry()
+	reg.MustRegister(&staticCheck{name: "a"}, nil)
+	b := newSeriesCheck("b")
+	gaugeB := newTestGauge("test_reload_b")
+	reg.MustRegister(b, gaugeB)
+	RunChecks(context.Background(), reg, cfg)
+	ticker := time.NewTicker(cfg.CheckInterval)
+	defer ticker.Stop()
+
+	// An unknown check name is rejected and counted; nothing changes.
+	failures := testutil.ToFloat64(metrics.ConfigReloadFailures)
+	if err := os.WriteFile(path, []byte("disabledChecks: [typo]"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg = reloadConfig(reg, reloader, cfg, ticker)
+	if got := testutil.ToFloat64(metrics.ConfigReloadFailures) - failures; got != 1 {
+		t.Errorf("expected 1 reload failure, got %v", got)
+	}
+	if len(reg.Enabled()) != 2 {
+		t.Errorf("expected both checks to stay enabled, got %d", len(reg.Enabled()))
+	}
+
+	if err := os.WriteFile(path, []byte("checks: {b: {enabled: false}}"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg = reloadConfig(reg, reloader, cfg, ticker)
+	if enabled := reg.Enabled(); len(enabled) != 1 || enabled[0].Name() != "a" {
+		t.Errorf("expected only check a to be enabled after reload, got %v", enabled)
+	}
+	if got := testutil.ToFloat64(metrics.ConfigHash); got != float64(cfg.Hash()) {
+		t.Errorf("expected config hash metric %v, got %v", float64(cfg.Hash()), got)
+	}
+
+	// The disabled check no longer reports its last, unhealthy result.
+	if got := testutil.ToFloat64(gaugeB); got != 0 {
+		t.Errorf("expected the disabled check's gauge to be cleared, got %v", got)
+	}
+	if got := testutil.CollectAndCount(b.vec); got != 0 {
+		t.Errorf("expected the disabled check's series to be deleted, got %d series", got)
+	}
+	if metrics.CheckUnknown.DeleteLabelValues("b") || metrics.CheckRawUnhealthy.DeleteLabelValues("b") {
+		t.Error("expected the disabled check's health_checker_* series to be deleted")
+	}
+}
+
+func TestRunChecks_PublishesGauges(t *testing.T) {
+	reg := NewRegistry()
+	healthy := newTestGauge("test_healthy")
+	unhealthy := newTestGauge("test_unhealthy")
+	unhealthy.Set(0)
+	healthy.Set(1)
+	reg.MustRegister(&staticCheck{name: "healthy", status: StatusHealthy}, healthy)
+	reg.MustRegister(&staticCheck{name: "unhealthy", status: StatusUnhealthy}, unhealthy)
+
+	results := RunChecks(context.Background(), reg, config.Config{})
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+	if got := testutil.ToFloat64(healthy); got != 0 {
+		t.Errorf("expected healthy gauge=0, got %v", got)
+	}
+	if got := testutil.ToFloat64(unhealthy); got != 1 {
+		t.Errorf("expected unhealthy gauge=1, got %v", got)
+	}
+}
+
+func TestRunChecks_StoresLatest(t *testing.T) {
+	reg := NewRegistry()
+	reg.MustRegister(&staticCheck{name: "healthy", status: StatusHealthy}, nil)
+	reg.MustRegister(&staticCheck{name: "unhealthy", status: StatusUnhealthy}, nil)
+
+	if _, ok := reg.Latest(); ok {
+		t.Fatal("expected no snapshot before the first cycle")
+	}
+	results := RunChecks(context.Background(), reg, config.Config{})
+	snapshot, ok := reg.Latest()
+	if !ok {
+		t.Fatal("expected a snapshot after the first cycle")
+	}
+	if snapshot.Status != StatusUnhealthy || snapshot.Time.IsZero() || !reflect.DeepEqual(snapshot.Checks, results) {
+		t.Errorf("expected unhealthy snapshot of the cycle's results, got %+v", snapshot)
+	}
+}
+
+func TestRegistry_Reset(t *testing.T) {
+	reg := NewRegistry()
+	check := newSeriesCheck("reset_test")
+	gauge := newTestGauge("test_reset")
+	reg.MustRegister(check, gauge)
+
+	RunChecks(context.Background(), reg, config.Config{})
+	if got := testutil.ToFloat64(gauge); got != 1 {
+		t.Fatalf("expected gauge=1 after an unhealthy run, got %v", got)
+	}
+
+	reg.Reset()
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("expected gauge=0 after reset, got %v", got)
+	}
+	if got := testutil.CollectAndCount(check.vec); got != 0 {
+		t.Errorf("expected the check's series to be deleted, got %d series", got)
+	}
+	if metrics.CheckRawUnhealthy.DeleteLabelValues("reset_test") || metrics.CheckLastSuccess.DeleteLabelValues("reset_test") {
+		t.Error("expected the check's health_checker_* series to be deleted")
+	}
+	if _, ok := reg.Latest(); ok {
+		t.Error("expected no snapshot after reset")
+	}
+
+	// Hysteresis starts afresh: one unhealthy result is enough again.
+	RunChecks(context.Background(), reg, config.Config{})
+	if got := testutil.ToFloat64(gauge); got != 1 {
+		t.Errorf("expected gauge=1 after the next unhealthy run, got %v", got)
+	}
+}
+
+func TestPublish_UnknownHoldsGauge(t *testing.T) {
+	gauge := newTestGauge("test_hold")
+	e := &entry{check: &staticCheck{name: "hold"}, gauge: gauge}
+
+	e.publish(Result{Check: "hold", Status: StatusUnhealthy}, config.Config{UnknownGaugeMode: config.UnknownGaugeHold})
+	e.publish(Result{Check: "hold", Status: StatusUnknown}, config.Config{UnknownGaugeMode: config.UnknownGaugeHold})
+	if got := testutil.ToFloat64(gauge); got != 1 {
+		t.Errorf("expected gauge to hold last value 1, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.CheckUnknown.WithLabelValues("hold")); got != 1 {
+		t.Errorf("expected health_checker_check_unknown=1, got %v", got)
+	}
+
+	e.publish(Result{Check: "hold", Status: StatusHealthy}, config.Config{UnknownGaugeMode: config.UnknownGaugeHold})
+	e.publish(Result{Check: "hold", Status: StatusUnknown}, config.Config{UnknownGaugeMode: config.UnknownGaugeHold})
+	if got := testutil.ToFloat64(gauge); got != 0 {
+		t.Errorf("expected gauge to hold last value 0, got %v", got)
+	}
+
+	e.publish(Result{Check: "hold", Status: StatusHealthy}, config.Config{UnknownGaugeMode: config.UnknownGaugeHold})
+	if got := testutil.ToFloat64(metrics.CheckUnknown.WithLabelValues("hold")); got != 0 {
+		t.Errorf("expected health_checker_check_unknown=0 after a known result, got %v", got)
+	}
+}
+
+func TestPublish_UnknownFailClosed(t *testing.T) {
+	gauge := newTestGauge("test_fail_closed")
+	e := &entry{check: &staticCheck{name: "failclosed"}, gauge: gauge}
+
+	e.publish(Result{Check: "failclosed", Status: StatusUnknown}, config.Config{UnknownGaugeMode: config.UnknownGaugeFailClosed})
+	if got := testutil.ToFloat64(gauge); got != 1 {
+		t.Errorf("expected fail-closed gauge=1 for unknown result, got %v", got)
+	}
+}
+
+func TestPublish_Hysteresis(t *testing.T) {
+	gauge := newTestGauge("test_hysteresis")
+	e := &entry{check: &staticCheck{name: "hysteresis"}, gauge: gauge}
+	cfg := config.Config{
+		UnknownGaugeMode: config.UnknownGaugeHold,
+		CheckHysteresis:  map[string]config.Hysteresis{"hysteresis": {UnhealthyCycles: 3, HealthyCycles: 2}},
+	}
+	flaps := testutil.ToFloat64(metrics.CheckFlaps.WithLabelValues("hysteresis"))
+
+	steps := []struct {
+		status Status
+		gauge  float64
+	}{
+		{StatusUnhealthy, 0},
+		{StatusHealthy, 0}, // breaks the run of unhealthy results
+		{StatusUnhealthy, 0},
+		{StatusUnhealthy, 0},
+		{StatusUnknown, 0}, // neither counts nor breaks the run
+		{StatusUnhealthy, 1},
+		{StatusHealthy, 1},
+		{StatusUnhealthy, 1},
+		{StatusHealthy, 1},
+		{StatusHealthy, 0},
+	}
+	for i, step := range steps {
+		e.publish(Result{Check: "hysteresis", Status: step.status}, cfg)
+		if got := testutil.ToFloat64(gauge); got != step.gauge {
+			t.Errorf("step %d (%s): expected gauge=%v, got %v", i, step.status, step.gauge, got)
+		}
+	}
+
+	if got := testutil.ToFloat64(metrics.CheckRawUnhealthy.WithLabelValues("hysteresis")); got != 0 {
+		t.Errorf("expected health_checker_check_raw_unhealthy=0 after a healthy result, got %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.CheckFlaps.WithLabelValues("hysteresis")) - flaps; got != 5 {
+		t.Errorf("expected 5 flaps, got %v", got)
+	}
+}
@@ -0,0 +1,154 @@
+package checker
+
+import (
+	"context"
+
+	configv1 "github.com/openshift/api/config/v1"
+	mcfgv1 "github.com/openshift/api/machineconfiguration/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+	mcfgv1client "github.com/openshift/client-go/machineconfiguration/clientset/versioned/typed/machineconfiguration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	certificatesv1 "k8s.io/api/certificates/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Source provides the cluster objects that checks evaluate. Checks read
+// through a Source rather than a client so the same check logic works against
+// live List calls (polling mode) and informer caches (watch mode).
+//
+// Returned objects are shared and must not be modified.
+type Source interface {
+	ListClusterOperators(ctx context.Context) ([]*configv1.ClusterOperator, error)
+	GetClusterVersion(ctx context.Context, name string) (*configv1.ClusterVersion, error)
+	ListNodes(ctx context.Context) ([]*corev1.Node, error)
+	ListNamespaces(ctx context.Context) ([]*corev1.Namespace, error)
+	ListPods(ctx context.Context, namespace string) ([]*corev1.Pod, error)
+	ListDeployments(ctx context.Context, namespace string) ([]*appsv1.Deployment, error)
+	ListDaemonSets(ctx context.Context, namespace string) ([]*appsv1.DaemonSet, error)
+	ListStatefulSets(ctx context.Context, namespace string) ([]*appsv1.StatefulSet, error)
+	ListJobs(ctx context.Context, namespace string) ([]*batchv1.Job, error)
+	ListMachineConfigPools(ctx context.Context) ([]*mcfgv1.MachineConfigPool, error)
+	ListTLSSecrets(ctx context.Context, namespace string) ([]*corev1.Secret, error)
+	ListCertificateSigningRequests(ctx context.Context) ([]*certificatesv1.CertificateSigningRequest, error)
+}
+
+// apiSource is a Source that issues a List/Get call to the API server for
+// every request.
+type apiSource struct {
+	k8s  kubernetes.Interface
+	ocp  configv1client.ConfigV1Interface
+	mcfg mcfgv1client.MachineconfigurationV1Interface
+}
+
+// NewAPISource returns a Source that reads directly from the API server.
+func NewAPISource(k8sClient kubernetes.Interface, ocpClient configv1client.ConfigV1Interface, mcfgClient mcfgv1client.MachineconfigurationV1Interface) Source {
+	return &apiSource{k8s: k8sClient, ocp: ocpClient, mcfg: mcfgClient}
+}
+
+func (s *apiSource) ListClusterOperators(ctx context.Context) ([]*configv1.ClusterOperator, error) {
+	list, err := s.ocp.ClusterOperators().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) GetClusterVersion(ctx context.Context, name string) (*configv1.ClusterVersion, error) {
+	return s.ocp.ClusterVersions().Get(ctx, name, metav1.GetOptions{})
+}
+
+func (s *apiSource) ListNodes(ctx context.Context) ([]*corev1.Node, error) {
+	list, err := s.k8s.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListNamespaces(ctx context.Context) ([]*corev1.Namespace, error) {
+	list, err := s.k8s.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListPods(ctx context.Context, namespace string) ([]*corev1.Pod, error) {
+	list, err := s.k8s.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListDeployments(ctx context.Context, namespace string) ([]*appsv1.Deployment, error) {
+	list, err := s.k8s.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListDaemonSets(ctx context.Context, namespace string) ([]*appsv1.DaemonSet, error) {
+	list, err := s.k8s.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListStatefulSets(ctx context.Context, namespace string) ([]*appsv1.StatefulSet, error) {
+	list, err := s.k8s.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListJobs(ctx context.Context, namespace string) ([]*batchv1.Job, error) {
+	list, err := s.k8s.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListMachineConfigPools(ctx context.Context) ([]*mcfgv1.MachineConfigPool, error) {
+	list, err := s.mcfg.MachineConfigPools().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+// ListTLSSecrets lists only Secrets of type kubernetes.io/tls, filtered by the
+// API server.
+func (s *apiSource) ListTLSSecrets(ctx context.Context, namespace string) ([]*corev1.Secret, error) {
+	list, err := s.k8s.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "type=" + string(corev1.SecretTypeTLS),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+func (s *apiSource) ListCertificateSigningRequests(ctx context.Context) ([]*certificatesv1.CertificateSigningRequest, error) {
+	list, err := s.k8s.CertificatesV1().CertificateSigningRequests().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return pointers(list.Items), nil
+}
+
+// pointers returns a pointer to each element of items, without copying them.
+func pointers[T any](items []T) []*T {
+	result := make([]*T, len(items))
+	for i := range items {
+		result[i] = &items[i]
+	}
+	return result
+}
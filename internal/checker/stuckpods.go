@@ -0,0 +1,144 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// podUnschedulable is the stuck state of a Pending pod that has not been
+// scheduled. The other stuck states are container waiting reasons.
+const podUnschedulable = "Unschedulable"
+
+// stuckPodsCheck reports whether any pod in a system namespace has been stuck
+// before running for longer than allowed (openshift_system_pods_stuck).
+// podFailureReason deliberately treats these states as healthy, because every pod
+// passes through them; only their duration makes them a problem.
+type stuckPodsCheck struct {
+	source Source
+
+	// stuck holds the openshift_system_pod_stuck_seconds series.
+	stuck *metrics.Series
+
+	now func() time.Time
+}
+
+// NewStuckPodsCheck returns the "stuckpods" check.
+func NewStuckPodsCheck(source Source) Check {
+	return &stuckPodsCheck{source: source, stuck: metrics.NewSeries(metrics.SystemPodStuckSeconds), now: time.Now}
+}
+
+// Name implements Check.
+func (c *stuckPodsCheck) Name() string { return "stuckpods" }
+
+// reset implements resetter.
+func (c *stuckPodsCheck) reset() {
+	c.stuck.Reset()
+}
+
+// Run lists pods in each system namespace and reports every pod that has been
+// in a stuck state (see podStuckState) for longer than cfg.StuckPodAges allows
+// for that state. Every such pod is published to
+// openshift_system_pod_stuck_seconds with the time spent in the state.
+//
+// On API error for any namespace, the result is unknown.
+func (c *stuckPodsCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+	now := c.now()
+
+	namespaces, err := c.source.ListNamespaces(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list Namespaces: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Namespaces: %w", err))
+		return result
+	}
+
+	var samples []metrics.Sample
+	for _, ns := range namespaces {
+		if !IsSystemNamespace(ns.Name, cfg) {
+			continue
+		}
+
+		pods, err := c.source.ListPods(ctx, ns.Name)
+		if err != nil {
+			log.Printf("WARNING: failed to list Pods in namespace %q: %v — check status unknown", ns.Name, err)
+			result.fail(fmt.Errorf("failed to list Pods in namespace %q: %w", ns.Name, err))
+			return result
+		}
+
+		for _, pod := range pods {
+			state, since := podStuckState(*pod)
+			maxAge, ok := cfg.StuckPodAges[state]
+			if !ok {
+				continue
+			}
+			age := now.Sub(since)
+			if age <= maxAge {
+				continue
+			}
+			reason := fmt.Sprintf("Pod %q in namespace %q has been %s for %s", pod.Name, pod.Namespace, state, age.Truncate(time.Second))
+			log.Printf("WARNING: %s", reason)
+			result.addFailure(ObjectRef{Kind: "Pod", Namespace: pod.Namespace, Name: pod.Name}, reason)
+			samples = append(samples, metrics.Sample{Labels: []string{pod.Namespace, pod.Name, state}, Value: age.Seconds()})
+		}
+	}
+	c.stuck.Update(samples)
+
+	return result
+}
+
+// podStuckState returns the state a pod that has not started is in, and
+// since when, or an empty state if the pod is not waiting to start:
+//
+//   - Unschedulable for a Pending pod with PodScheduled=False, since that
+//     condition's last transition;
+//   - otherwise the waiting reason of the first (init) container that is
+//     waiting, since the pod's ContainersReady condition last became False.
+//
+// Waiting states carry no timestamp, so ContainersReady (falling back to
+// PodScheduled, then the pod's creation) approximates how long the pod's
+// containers have not been running. Terminating pods are ignored.
+func podStuckState(pod corev1.Pod) (string, time.Time) {
+	if pod.DeletionTimestamp != nil {
+		return "", time.Time{}
+	}
+
+	since := pod.CreationTimestamp.Time
+	if pod.Status.Phase == corev1.PodPending {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == corev1.PodScheduled && cond.Status == corev1.ConditionFalse {
+				return podUnschedulable, laterTime(since, cond.LastTransitionTime.Time)
+			}
+		}
+	}
+
+	for _, condType := range []corev1.PodConditionType{corev1.PodScheduled, corev1.ContainersReady} {
+		for _, cond := range pod.Status.Conditions {
+			if cond.Type == condType {
+				since = laterTime(since, cond.LastTransitionTime.Time)
+			}
+		}
+	}
+	for _, statuses := range [][]corev1.ContainerStatus{pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses} {
+		for _, cs := range statuses {
+			if cs.State.Waiting != nil && cs.State.Waiting.Reason != "" {
+				return cs.State.Waiting.Reason, since
+			}
+		}
+	}
+	return "", time.Time{}
+}
+
+// laterTime returns the later of a and b.
+func laterTime(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
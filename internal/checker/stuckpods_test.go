@@ -0,0 +1,90 @@
+package checker
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// makeWaitingPod returns a scheduled pod created at created whose container is
+// waiting with reason.
+func makeWaitingPod(name, namespace, reason string, created time.Time) *corev1.Pod {
+	pod := makePod(name, namespace, corev1.PodPending, []corev1.ContainerStatus{
+		{Name: "app", State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}}},
+	})
+	pod.CreationTimestamp = metav1.NewTime(created)
+	pod.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodScheduled, Status: corev1.ConditionTrue, LastTransitionTime: metav1.NewTime(created)},
+		{Type: corev1.ContainersReady, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(created)},
+	}
+	return &pod
+}
+
+func TestPodStuckState(t *testing.T) {
+	created := time.Now().Add(-time.Hour)
+
+	unschedulable := makePod("p", "openshift-monitoring", corev1.PodPending, nil)
+	unschedulable.CreationTimestamp = metav1.NewTime(created)
+	unschedulable.Status.Conditions = []corev1.PodCondition{
+		{Type: corev1.PodScheduled, Status: corev1.ConditionFalse, LastTransitionTime: metav1.NewTime(created.Add(time.Minute))},
+	}
+	if state, since := podStuckState(unschedulable); state != podUnschedulable || !since.Equal(created.Add(time.Minute)) {
+		t.Errorf("expected Unschedulable since the PodScheduled transition, got %q since %v", state, since)
+	}
+
+	// A running pod whose container restarted into ImagePullBackOff is stuck
+	// since its containers last became not ready.
+	restarted := makeWaitingPod("p", "openshift-monitoring", "ImagePullBackOff", created)
+	restarted.Status.Phase = corev1.PodRunning
+	restarted.Status.Conditions[1].LastTransitionTime = metav1.NewTime(created.Add(30 * time.Minute))
+	if state, since := podStuckState(*restarted); state != "ImagePullBackOff" || !since.Equal(created.Add(30*time.Minute)) {
+		t.Errorf("expected ImagePullBackOff since the ContainersReady transition, got %q since %v", state, since)
+	}
+
+	running := makePod("p", "openshift-monitoring", corev1.PodRunning, []corev1.ContainerStatus{
+		{Name: "app", State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+	})
+	if state, _ := podStuckState(running); state != "" {
+		t.Errorf("expected a running pod not to be stuck, got %q", state)
+	}
+
+	terminating := makeWaitingPod("p", "openshift-monitoring", "ContainerCreating", created)
+	terminating.DeletionTimestamp = &metav1.Time{Time: created}
+	if state, _ := podStuckState(*terminating); state != "" {
+		t.Errorf("expected a terminating pod to be ignored, got %q", state)
+	}
+}
+
+func TestStuckPodsCheck(t *testing.T) {
+	now := time.Now()
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-monitoring"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-app"}},
+		makeWaitingPod("stuck", "openshift-monitoring", "ContainerCreating", now.Add(-20*time.Minute)),
+		makeWaitingPod("starting", "openshift-monitoring", "ContainerCreating", now.Add(-time.Minute)),
+		makeWaitingPod("crashing", "openshift-monitoring", "CrashLoopBackOff", now.Add(-time.Hour)),
+		makeWaitingPod("user-pod", "my-app", "ImagePullBackOff", now.Add(-time.Hour)),
+	)
+	check := NewStuckPodsCheck(NewAPISource(client, nil, nil)).(*stuckPodsCheck)
+	check.now = func() time.Time { return now }
+	cfg := config.Config{SystemNamespacePrefixes: []string{"openshift-"}, StuckPodAges: config.DefaultStuckPodAges}
+
+	result := check.Run(context.Background(), cfg)
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	if len(result.Affected) != 1 || result.Affected[0].Name != "stuck" {
+		t.Errorf("expected stuck to be the only affected pod, got %v", result.Affected)
+	}
+	if got := testutil.ToFloat64(metrics.SystemPodStuckSeconds.WithLabelValues("openshift-monitoring", "stuck", "ContainerCreating")); got != 1200 {
+		t.Errorf("expected openshift_system_pod_stuck_seconds=1200, got %v", got)
+	}
+}
@@ -0,0 +1,167 @@
+package checker
+
+import (
+	"regexp"
+	"strconv"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// revisionPodName matches the pods that static pod operators (kube-apiserver,
+// etcd, ...) run on each node to roll out or prune a revision, such as
+// "installer-7-master-0", "installer-7-retry-1-master-0" and
+// "revision-pruner-7-master-0".
+var revisionPodName = regexp.MustCompile(`^(installer|revision-pruner)-(\d+)(?:-retry-(\d+))?-(.+)$`)
+
+// revisionAttempt identifies one attempt of an installer or revision-pruner pod.
+type revisionAttempt struct {
+	kind     string
+	node     string
+	revision int
+	retry    int
+}
+
+// parseRevisionPod returns the attempt a pod name stands for, and false if the
+// pod is not an installer or revision-pruner pod.
+func parseRevisionPod(name string) (revisionAttempt, bool) {
+	m := revisionPodName.FindStringSubmatch(name)
+	if m == nil {
+		return revisionAttempt{}, false
+	}
+	revision, err := strconv.Atoi(m[2])
+	if err != nil {
+		return revisionAttempt{}, false
+	}
+	retry := 0
+	if m[3] != "" {
+		if retry, err = strconv.Atoi(m[3]); err != nil {
+			return revisionAttempt{}, false
+		}
+	}
+	return revisionAttempt{kind: m[1], node: m[4], revision: revision, retry: retry}, true
+}
+
+// newerThan reports whether a is a later attempt than b of the same kind on
+// the same node.
+func (a revisionAttempt) newerThan(b revisionAttempt) bool {
+	if a.revision != b.revision {
+		return a.revision > b.revision
+	}
+	return a.retry > b.retry
+}
+
+// supersededPods returns the names of the pods in one namespace whose failure
+// is no longer current, because a later attempt of the same work exists:
+//
+//   - installer and revision-pruner pods for whose node a later attempt has
+//     succeeded (a later attempt that also failed, or has not finished,
+//     supersedes nothing);
+//   - pods of a Job that has completed;
+//   - pods of a Job owned by a CronJob for which a newer Job has completed;
+//   - pods of a Job other than its most recently created pod.
+//
+// jobs are the Jobs in the namespace; pods whose Job is not among them are
+// judged by the pods alone.
+func supersededPods(pods []*corev1.Pod, jobs []*batchv1.Job) map[string]bool {
+	superseded := make(map[string]bool)
+
+	// Latest successful installer/pruner attempt per kind and node.
+	latestSucceeded := make(map[string]revisionAttempt)
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodSucceeded {
+			continue
+		}
+		if a, ok := parseRevisionPod(pod.Name); ok {
+			key := a.kind + "/" + a.node
+			if cur, ok := latestSucceeded[key]; !ok || a.newerThan(cur) {
+				latestSucceeded[key] = a
+			}
+		}
+	}
+
+	// Jobs by name, and the newest completed Job per CronJob.
+	jobsByName := make(map[string]*batchv1.Job, len(jobs))
+	newestComplete := make(map[string]metav1.Time)
+	for _, job := range jobs {
+		jobsByName[job.Name] = job
+		if cronJob := controllerName(job.OwnerReferences, "CronJob"); cronJob != "" && jobComplete(job) {
+			if cur, ok := newestComplete[cronJob]; !ok || cur.Before(&job.CreationTimestamp) {
+				newestComplete[cronJob] = job.CreationTimestamp
+			}
+		}
+	}
+
+	// Most recently created pod per Job.
+	latestPod := make(map[string]*corev1.Pod)
+	for _, pod := range pods {
+		if job := controllerName(pod.OwnerReferences, "Job"); job != "" {
+			if cur, ok := latestPod[job]; !ok || newerPod(pod, cur) {
+				latestPod[job] = pod
+			}
+		}
+	}
+
+	for _, pod := range pods {
+		if a, ok := parseRevisionPod(pod.Name); ok {
+			if succeeded, ok := latestSucceeded[a.kind+"/"+a.node]; ok && succeeded.newerThan(a) {
+				superseded[pod.Name] = true
+			}
+			continue
+		}
+
+		jobName := controllerName(pod.OwnerReferences, "Job")
+		if jobName == "" {
+			continue
+		}
+		if latestPod[jobName] != pod {
+			superseded[pod.Name] = true
+			continue
+		}
+		job, ok := jobsByName[jobName]
+		if !ok {
+			continue
+		}
+		if jobComplete(job) {
+			superseded[pod.Name] = true
+			continue
+		}
+		if cronJob := controllerName(job.OwnerReferences, "CronJob"); cronJob != "" {
+			if newest, ok := newestComplete[cronJob]; ok && job.CreationTimestamp.Before(&newest) {
+				superseded[pod.Name] = true
+			}
+		}
+	}
+	return superseded
+}
+
+// controllerName returns the name of the owner of the given kind, or empty
+// string if there is none.
+func controllerName(owners []metav1.OwnerReference, kind string) string {
+	for _, owner := range owners {
+		if owner.Kind == kind {
+			return owner.Name
+		}
+	}
+	return ""
+}
+
+// jobComplete reports whether the Job has condition Complete=True.
+func jobComplete(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobComplete && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// newerPod reports whether a was created after b, breaking ties by name so
+// the result does not depend on list order.
+func newerPod(a, b *corev1.Pod) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return b.CreationTimestamp.Before(&a.CreationTimestamp)
+	}
+	return a.Name > b.Name
+}
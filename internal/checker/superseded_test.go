@@ -0,0 +1,185 @@
+package checker
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+)
+
+func makeJob(name, cronJob string, created time.Time, complete bool) *batchv1.Job {
+	job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+		Name:              name,
+		Namespace:         "openshift-operator-lifecycle-manager",
+		CreationTimestamp: metav1.NewTime(created),
+	}}
+	if cronJob != "" {
+		job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: cronJob}}
+	}
+	if complete {
+		job.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+	}
+	return job
+}
+
+func makeJobPod(name, job string, created time.Time, phase corev1.PodPhase) *corev1.Pod {
+	pod := makePod(name, "openshift-operator-lifecycle-manager", phase, nil)
+	pod.CreationTimestamp = metav1.NewTime(created)
+	pod.OwnerReferences = []metav1.OwnerReference{{Kind: "Job", Name: job}}
+	return &pod
+}
+
+func TestParseRevisionPod(t *testing.T) {
+	tests := []struct {
+		name string
+		want revisionAttempt
+		ok   bool
+	}{
+		{"installer-7-master-0", revisionAttempt{kind: "installer", node: "master-0", revision: 7}, true},
+		{"installer-7-retry-2-ip-10-0-1-2.ec2.internal", revisionAttempt{kind: "installer", node: "ip-10-0-1-2.ec2.internal", revision: 7, retry: 2}, true},
+		{"revision-pruner-12-master-1", revisionAttempt{kind: "revision-pruner", node: "master-1", revision: 12}, true},
+		{"kube-apiserver-master-0", revisionAttempt{}, false},
+		{"installer-master-0", revisionAttempt{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRevisionPod(tt.name)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("parseRevisionPod(%q) = %+v, %v; want %+v, %v", tt.name, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func makeRevisionPods(phases map[string]corev1.PodPhase) []*corev1.Pod {
+	pods := []*corev1.Pod{}
+	for name, phase := range phases {
+		pod := makePod(name, "openshift-kube-apiserver", phase, nil)
+		pods = append(pods, &pod)
+	}
+	return pods
+}
+
+func TestSupersededPods_RevisionPods(t *testing.T) {
+	pods := makeRevisionPods(map[string]corev1.PodPhase{
+		"installer-6-master-0":         corev1.PodFailed,
+		"installer-7-master-0":         corev1.PodFailed,
+		"installer-7-retry-1-master-0": corev1.PodSucceeded,
+		"installer-5-master-1":         corev1.PodFailed,
+		"revision-pruner-7-master-0":   corev1.PodSucceeded,
+		"revision-pruner-6-master-0":   corev1.PodFailed,
+		"kube-apiserver-master-0":      corev1.PodFailed,
+	})
+
+	got := supersededPods(pods, nil)
+	want := map[string]bool{
+		"installer-6-master-0":       true,
+		"installer-7-master-0":       true,
+		"revision-pruner-6-master-0": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected superseded %v, got %v", want, got)
+	}
+}
+
+func TestSupersededPods_RevisionPodsFailingRepeatedly(t *testing.T) {
+	// Two failed attempts in a row, then one that has not finished: only a
+	// successful attempt supersedes a failure, so both failures stay current.
+	pods := makeRevisionPods(map[string]corev1.PodPhase{
+		"installer-7-master-0": corev1.PodFailed,
+		"installer-8-master-0": corev1.PodFailed,
+		"installer-9-master-0": corev1.PodPending,
+	})
+
+	if got := supersededPods(pods, nil); len(got) != 0 {
+		t.Errorf("expected no superseded pods, got %v", got)
+	}
+
+	// Once an attempt succeeds, every earlier failure is superseded.
+	pods = append(pods, makeRevisionPods(map[string]corev1.PodPhase{"installer-9-retry-1-master-0": corev1.PodSucceeded})...)
+	got := supersededPods(pods, nil)
+	want := map[string]bool{
+		"installer-7-master-0": true,
+		"installer-8-master-0": true,
+		"installer-9-master-0": true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected superseded %v, got %v", want, got)
+	}
+}
+
+func TestSupersededPods_JobPods(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	jobs := []*batchv1.Job{
+		makeJob("collect-profiles-1", "collect-profiles", base, false),
+		makeJob("collect-profiles-2", "collect-profiles", base.Add(15*time.Minute), true),
+		makeJob("collect-profiles-3", "collect-profiles", base.Add(30*time.Minute), false),
+		makeJob("retried", "", base, false),
+		makeJob("finished", "", base, true),
+	}
+	pods := []*corev1.Pod{
+		// Older than the completed collect-profiles-2.
+		makeJobPod("collect-profiles-1-aaaaa", "collect-profiles-1", base, corev1.PodFailed),
+		makeJobPod("collect-profiles-2-bbbbb", "collect-profiles-2", base.Add(15*time.Minute), corev1.PodSucceeded),
+		// Newer than the last completed Job: still current.
+		makeJobPod("collect-profiles-3-ccccc", "collect-profiles-3", base.Add(30*time.Minute), corev1.PodFailed),
+		// Only the latest pod of a running Job counts.
+		makeJobPod("retried-aaaaa", "retried", base, corev1.PodFailed),
+		makeJobPod("retried-bbbbb", "retried", base.Add(time.Minute), corev1.PodFailed),
+		// The Job eventually succeeded.
+		makeJobPod("finished-aaaaa", "finished", base, corev1.PodFailed),
+		// The Job is gone: judged by the pod alone.
+		makeJobPod("orphan-aaaaa", "orphan", base, corev1.PodFailed),
+	}
+
+	got := supersededPods(pods, jobs)
+	want := map[string]bool{
+		"collect-profiles-1-aaaaa": true,
+		"collect-profiles-2-bbbbb": true,
+		"retried-aaaaa":            true,
+		"finished-aaaaa":           true,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected superseded %v, got %v", want, got)
+	}
+}
+
+func TestSystemPodsCheck_SupersededPods(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldInstaller := makePod("installer-6-master-0", "openshift-kube-apiserver", corev1.PodFailed, nil)
+	newInstaller := makePod("installer-7-master-0", "openshift-kube-apiserver", corev1.PodSucceeded, nil)
+	failedInstaller := makePod("installer-7-master-1", "openshift-kube-apiserver", corev1.PodFailed, nil)
+
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-kube-apiserver"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-operator-lifecycle-manager"}},
+		&oldInstaller, &newInstaller, &failedInstaller,
+		makeJob("collect-profiles-1", "collect-profiles", base, false),
+		makeJob("collect-profiles-2", "collect-profiles", base.Add(15*time.Minute), true),
+		makeJobPod("collect-profiles-1-aaaaa", "collect-profiles-1", base, corev1.PodFailed),
+	)
+	cfg := config.Config{
+		SystemNamespacePrefixes: []string{"openshift-"},
+		RestartWindow:           time.Hour,
+		RestartThreshold:        5,
+		PodRules:                config.PodRules{FatalReasons: config.DefaultFatalContainerReasons, FailedJobPods: config.FailedJobPodsReport},
+	}
+
+	result := NewSystemPodsCheck(NewAPISource(client, nil, nil)).Run(context.Background(), cfg)
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	got := map[string]bool{}
+	for _, ref := range result.Affected {
+		got[ref.Name] = true
+	}
+	want := map[string]bool{"installer-7-master-1": true}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected affected pods %v, got %v", want, got)
+	}
+}
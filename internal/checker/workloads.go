@@ -0,0 +1,192 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// Reasons reported for unhealthy workload controllers.
+const (
+	// workloadProgressDeadlineExceeded: a Deployment rollout made no progress
+	// within its progressDeadlineSeconds.
+	workloadProgressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+	// workloadUnavailable: a Deployment has Available=False, or a DaemonSet has
+	// more unavailable pods than its rollout allows.
+	workloadUnavailable = "Unavailable"
+
+	// workloadNotReady: a StatefulSet has fewer ready replicas than desired.
+	workloadNotReady = "NotReady"
+)
+
+// workloadsCheck reports whether any Deployment, DaemonSet or StatefulSet in a
+// system namespace is unavailable or not progressing
+// (openshift_system_workloads_unavailable).
+type workloadsCheck struct {
+	source Source
+
+	// unavailable holds the openshift_system_workload_unavailable series.
+	unavailable *metrics.Series
+}
+
+// NewWorkloadsCheck returns the "workloads" check.
+func NewWorkloadsCheck(source Source) Check {
+	return &workloadsCheck{source: source, unavailable: metrics.NewSeries(metrics.SystemWorkloadUnavailable)}
+}
+
+// Name implements Check.
+func (c *workloadsCheck) Name() string { return "workloads" }
+
+// reset implements resetter.
+func (c *workloadsCheck) reset() {
+	c.unavailable.Reset()
+}
+
+// Run lists the Deployments, DaemonSets and StatefulSets in each system
+// namespace and reports every controller that is unavailable or not
+// progressing (see deploymentFailureReason, daemonSetFailureReason and
+// statefulSetFailureReason). This catches problems that are not visible in pod
+// status, such as a DaemonSet whose pods cannot be scheduled. Every failing
+// controller is published to openshift_system_workload_unavailable.
+//
+// On API error for any namespace, the result is unknown.
+func (c *workloadsCheck) Run(ctx context.Context, cfg config.Config) Result {
+	result := newResult(c.Name())
+
+	namespaces, err := c.source.ListNamespaces(ctx)
+	if err != nil {
+		log.Printf("WARNING: failed to list Namespaces: %v — check status unknown", err)
+		result.fail(fmt.Errorf("failed to list Namespaces: %w", err))
+		return result
+	}
+
+	var samples []metrics.Sample
+	report := func(kind, namespace, name, reason, detail string) {
+		message := fmt.Sprintf("%s %q in namespace %q is %s: %s", kind, name, namespace, reason, detail)
+		log.Printf("WARNING: %s", message)
+		result.addFailure(ObjectRef{Kind: kind, Namespace: namespace, Name: name}, message)
+		samples = append(samples, metrics.Sample{Labels: []string{namespace, kind, name, reason}, Value: 1})
+	}
+
+	for _, ns := range namespaces {
+		if !IsSystemNamespace(ns.Name, cfg) {
+			continue
+		}
+
+		deployments, err := c.source.ListDeployments(ctx, ns.Name)
+		if err != nil {
+			log.Printf("WARNING: failed to list Deployments in namespace %q: %v — check status unknown", ns.Name, err)
+			result.fail(fmt.Errorf("failed to list Deployments in namespace %q: %w", ns.Name, err))
+			return result
+		}
+		for _, d := range deployments {
+			if reason, detail := deploymentFailureReason(*d); reason != "" {
+				report("Deployment", d.Namespace, d.Name, reason, detail)
+			}
+		}
+
+		daemonSets, err := c.source.ListDaemonSets(ctx, ns.Name)
+		if err != nil {
+			log.Printf("WARNING: failed to list DaemonSets in namespace %q: %v — check status unknown", ns.Name, err)
+			result.fail(fmt.Errorf("failed to list DaemonSets in namespace %q: %w", ns.Name, err))
+			return result
+		}
+		for _, ds := range daemonSets {
+			if reason, detail := daemonSetFailureReason(*ds); reason != "" {
+				report("DaemonSet", ds.Namespace, ds.Name, reason, detail)
+			}
+		}
+
+		statefulSets, err := c.source.ListStatefulSets(ctx, ns.Name)
+		if err != nil {
+			log.Printf("WARNING: failed to list StatefulSets in namespace %q: %v — check status unknown", ns.Name, err)
+			result.fail(fmt.Errorf("failed to list StatefulSets in namespace %q: %w", ns.Name, err))
+			return result
+		}
+		for _, sts := range statefulSets {
+			if reason, detail := statefulSetFailureReason(*sts); reason != "" {
+				report("StatefulSet", sts.Namespace, sts.Name, reason, detail)
+			}
+		}
+	}
+	c.unavailable.Update(samples)
+
+	return result
+}
+
+// deploymentFailureReason returns why the Deployment is unhealthy and a
+// human-readable detail, or empty strings if it is healthy: a rollout that
+// exceeded its progress deadline (Progressing=False, ProgressDeadlineExceeded),
+// or Available=False (fewer available replicas than the rollout allows).
+func deploymentFailureReason(d appsv1.Deployment) (string, string) {
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == corev1.ConditionFalse && cond.Reason == workloadProgressDeadlineExceeded {
+			return workloadProgressDeadlineExceeded, cond.Message
+		}
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentAvailable && cond.Status == corev1.ConditionFalse {
+			return workloadUnavailable, fmt.Sprintf("%d of %d replicas available", d.Status.AvailableReplicas, desiredReplicas(d.Spec.Replicas))
+		}
+	}
+	return "", ""
+}
+
+// daemonSetFailureReason returns why the DaemonSet is unhealthy and a
+// human-readable detail, or empty strings if it is healthy. Unavailable pods
+// are tolerated while a rollout is in progress, up to the rollout's
+// maxUnavailable.
+func daemonSetFailureReason(ds appsv1.DaemonSet) (string, string) {
+	unavailable := ds.Status.NumberUnavailable
+	if unavailable <= 0 {
+		return "", ""
+	}
+	desired := ds.Status.DesiredNumberScheduled
+	rollingOut := ds.Status.ObservedGeneration < ds.Generation || ds.Status.UpdatedNumberScheduled < desired
+	if rollingOut && unavailable <= daemonSetMaxUnavailable(ds) {
+		return "", ""
+	}
+	return workloadUnavailable, fmt.Sprintf("%d of %d pods unavailable", unavailable, desired)
+}
+
+// daemonSetMaxUnavailable returns the number of pods the DaemonSet's rolling
+// update may take down at once (default 1), rounded up like the controller does.
+func daemonSetMaxUnavailable(ds appsv1.DaemonSet) int32 {
+	maxUnavailable := intstr.FromInt32(1)
+	if ru := ds.Spec.UpdateStrategy.RollingUpdate; ru != nil && ru.MaxUnavailable != nil {
+		maxUnavailable = *ru.MaxUnavailable
+	}
+	n, err := intstr.GetScaledValueFromIntOrPercent(&maxUnavailable, int(ds.Status.DesiredNumberScheduled), true)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return int32(n)
+}
+
+// statefulSetFailureReason returns why the StatefulSet is unhealthy and a
+// human-readable detail, or empty strings if it is healthy: fewer ready
+// replicas than desired.
+func statefulSetFailureReason(sts appsv1.StatefulSet) (string, string) {
+	desired := desiredReplicas(sts.Spec.Replicas)
+	if sts.Status.ReadyReplicas >= desired {
+		return "", ""
+	}
+	return workloadNotReady, fmt.Sprintf("%d of %d replicas ready", sts.Status.ReadyReplicas, desired)
+}
+
+// desiredReplicas returns the replica count of a Deployment or StatefulSet
+// spec, which defaults to 1 when unset.
+func desiredReplicas(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,126 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+func int32Ptr(n int32) *int32 { return &n }
+
+func TestDeploymentFailureReason(t *testing.T) {
+	d := appsv1.Deployment{Spec: appsv1.DeploymentSpec{Replicas: int32Ptr(2)}}
+	d.Status.Conditions = []appsv1.DeploymentCondition{
+		{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue},
+		{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue, Reason: "NewReplicaSetAvailable"},
+	}
+	if reason, _ := deploymentFailureReason(d); reason != "" {
+		t.Errorf("expected a healthy Deployment, got %q", reason)
+	}
+
+	d.Status.Conditions[1] = appsv1.DeploymentCondition{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionFalse, Reason: workloadProgressDeadlineExceeded}
+	if reason, _ := deploymentFailureReason(d); reason != workloadProgressDeadlineExceeded {
+		t.Errorf("expected %q, got %q", workloadProgressDeadlineExceeded, reason)
+	}
+
+	d.Status.Conditions = []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse}}
+	if reason, _ := deploymentFailureReason(d); reason != workloadUnavailable {
+		t.Errorf("expected %q, got %q", workloadUnavailable, reason)
+	}
+}
+
+func TestDaemonSetFailureReason(t *testing.T) {
+	ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	ds.Status = appsv1.DaemonSetStatus{ObservedGeneration: 2, DesiredNumberScheduled: 6, UpdatedNumberScheduled: 6}
+	if reason, _ := daemonSetFailureReason(ds); reason != "" {
+		t.Errorf("expected a healthy DaemonSet, got %q", reason)
+	}
+
+	ds.Status.NumberUnavailable = 1
+	if reason, _ := daemonSetFailureReason(ds); reason != workloadUnavailable {
+		t.Errorf("expected an unavailable pod outside a rollout to be reported, got %q", reason)
+	}
+
+	// During a rollout, up to maxUnavailable (default 1) pods may be unavailable.
+	ds.Status.UpdatedNumberScheduled = 3
+	if reason, _ := daemonSetFailureReason(ds); reason != "" {
+		t.Errorf("expected 1 unavailable pod during a rollout to be tolerated, got %q", reason)
+	}
+	ds.Status.NumberUnavailable = 2
+	if reason, _ := daemonSetFailureReason(ds); reason != workloadUnavailable {
+		t.Errorf("expected 2 unavailable pods during a rollout to be reported, got %q", reason)
+	}
+	maxUnavailable := intstr.FromString("50%")
+	ds.Spec.UpdateStrategy.RollingUpdate = &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable}
+	if reason, _ := daemonSetFailureReason(ds); reason != "" {
+		t.Errorf("expected 2 of 6 unavailable pods to be tolerated with maxUnavailable=50%%, got %q", reason)
+	}
+}
+
+func TestStatefulSetFailureReason(t *testing.T) {
+	sts := appsv1.StatefulSet{Spec: appsv1.StatefulSetSpec{Replicas: int32Ptr(3)}}
+	sts.Status.ReadyReplicas = 3
+	if reason, _ := statefulSetFailureReason(sts); reason != "" {
+		t.Errorf("expected a healthy StatefulSet, got %q", reason)
+	}
+	sts.Status.ReadyReplicas = 2
+	if reason, _ := statefulSetFailureReason(sts); reason != workloadNotReady {
+		t.Errorf("expected %q, got %q", workloadNotReady, reason)
+	}
+}
+
+func TestWorkloadsCheck_SystemNamespacesOnly(t *testing.T) {
+	unavailable := []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionFalse}}
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "my-app"}},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "router-default", Namespace: "openshift-ingress"},
+			Status:     appsv1.DeploymentStatus{Conditions: unavailable},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "frontend", Namespace: "my-app"},
+			Status:     appsv1.DeploymentStatus{Conditions: unavailable},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "my-app"},
+			Spec:       appsv1.StatefulSetSpec{Replicas: int32Ptr(3)},
+		},
+	)
+	cfg := config.Config{SystemNamespacePrefixes: []string{"openshift-"}}
+
+	result := NewWorkloadsCheck(NewAPISource(client, nil, nil)).Run(context.Background(), cfg)
+	if result.Status != StatusUnhealthy {
+		t.Fatalf("expected unhealthy, got %+v", result)
+	}
+	if len(result.Affected) != 1 || result.Affected[0] != (ObjectRef{Kind: "Deployment", Namespace: "openshift-ingress", Name: "router-default"}) {
+		t.Errorf("expected router-default to be the only affected workload, got %v", result.Affected)
+	}
+	if got := testutil.ToFloat64(metrics.SystemWorkloadUnavailable.WithLabelValues("openshift-ingress", "Deployment", "router-default", workloadUnavailable)); got != 1 {
+		t.Errorf("expected openshift_system_workload_unavailable for router-default, got %v", got)
+	}
+}
+
+func TestWorkloadsCheck_APIErrorIsUnknown(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "openshift-ingress"}})
+	client.PrependReactor("list", "daemonsets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+
+	result := NewWorkloadsCheck(NewAPISource(client, nil, nil)).Run(context.Background(), config.Config{SystemNamespacePrefixes: []string{"openshift-"}})
+	if result.Status != StatusUnknown {
+		t.Errorf("expected unknown, got %+v", result)
+	}
+}
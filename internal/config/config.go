@@ -1,15 +1,166 @@
-// Package config provides environment variable parsing and validation
-// for the OpenShift cluster health-checker.
+// Package config provides environment variable and config file parsing and
+// validation for the OpenShift cluster health-checker.
 package config
 
 import (
 	"fmt"
 	"os"
+	"path"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// Values for Config.UnknownGaugeMode.
+const (
+	// UnknownGaugeHold leaves a check's binary gauge at its last value when the
+	// check cannot evaluate the cluster. health_checker_check_unknown reports the
+	// unknown state instead.
+	UnknownGaugeHold = "hold"
+
+	// UnknownGaugeFailClosed sets a check's binary gauge to 1 when the check
+	// cannot evaluate the cluster. This is the behaviour before tri-state results
+	// were introduced and is kept as a compatibility mode.
+	UnknownGaugeFailClosed = "fail-closed"
+)
+
+// Hysteresis is how many consecutive results of a check it takes to change
+// the check's binary gauge. Unknown results neither count nor break a run.
+// Results are counted per cycle, so in watch mode, where changes also start
+// cycles, a run can be as short as the cycle count times WatchDebounce.
+type Hysteresis struct {
+	// UnhealthyCycles is the number of consecutive unhealthy results that set
+	// the gauge to 1.
+	UnhealthyCycles int
+
+	// HealthyCycles is the number of consecutive healthy results that set the
+	// gauge back to 0.
+	HealthyCycles int
+}
+
+// NodeProblemNames are the node problems the nodeproblems check can evaluate:
+// the node pressure conditions, NetworkUnavailable, and Unschedulable for a
+// cordoned node. Taints are configured separately (Config.NodeProblemTaints).
+var NodeProblemNames = []string{"MemoryPressure", "DiskPressure", "PIDPressure", "NetworkUnavailable", "Unschedulable"}
+
+// DefaultStuckPodAges is how long a system pod may stay in each state before
+// the stuckpods check reports it. The keys are also the only accepted states:
+// Unschedulable (Pending and not scheduled), then the container waiting
+// reasons ContainerCreating, ImagePullBackOff, ErrImagePull and
+// CreateContainerConfigError.
+var DefaultStuckPodAges = map[string]time.Duration{
+	"Unschedulable":              15 * time.Minute,
+	"ContainerCreating":          10 * time.Minute,
+	"ImagePullBackOff":           10 * time.Minute,
+	"ErrImagePull":               10 * time.Minute,
+	"CreateContainerConfigError": 10 * time.Minute,
+}
+
+// DefaultFatalContainerReasons are the container waiting/terminated reasons
+// that make a pod failing unless FATAL_CONTAINER_REASONS says otherwise.
+var DefaultFatalContainerReasons = []string{"CrashLoopBackOff", "OOMKilled", "Error"}
+
+// Values for PodRules.FailedJobPods.
+const (
+	// FailedJobPodsReport reports Failed pods owned by a Job like any other
+	// Failed pod.
+	FailedJobPodsReport = "report"
+
+	// FailedJobPodsIgnore ignores Failed pods owned by a Job. The Job's
+	// retries, and the Job itself, decide whether the work failed.
+	FailedJobPodsIgnore = "ignore"
 )
 
+// PodRules control which pods the systempods check reports as failing.
+type PodRules struct {
+	// FatalReasons are the container waiting/terminated reasons that make a
+	// pod failing. Default: DefaultFatalContainerReasons.
+	FatalReasons []string
+
+	// FailedJobPods is FailedJobPodsReport (default) or FailedJobPodsIgnore.
+	FailedJobPods string
+
+	// ExcludeSelector is a label selector; matching pods are never reported.
+	// Default: "" (no pod is excluded by label).
+	ExcludeSelector string
+
+	// ExcludeOwnerKinds are owner kinds (e.g. "Job"); pods with an owner of
+	// one of these kinds are never reported. Default: [].
+	ExcludeOwnerKinds []string
+}
+
+// NamespacePodRules overrides PodRules for the namespaces matching any of
+// Namespaces (shell patterns, e.g. "openshift-kube-*"). Nil fields keep the
+// value from the global rules.
+type NamespacePodRules struct {
+	Namespaces        []string
+	FatalReasons      []string
+	FailedJobPods     *string
+	ExcludeSelector   *string
+	ExcludeOwnerKinds []string
+}
+
+// DefaultOperatorGroupSeverity is the severity of an OperatorGroup that does
+// not set one.
+const DefaultOperatorGroupSeverity = "warning"
+
+// OperatorGroup is a user-defined group of ClusterOperators whose degradation
+// its own check (operatorgroup/<name>) reports under the group's own name and
+// severity.
+type OperatorGroup struct {
+	// Name identifies the group (the group label).
+	Name string
+
+	// Operators are ClusterOperator names or shell patterns (e.g. "kube-*").
+	Operators []string
+
+	// Severity is passed through as the severity label, for alert routing.
+	// Default: DefaultOperatorGroupSeverity.
+	Severity string
+}
+
+// Includes reports whether the named ClusterOperator belongs to the group.
+func (g OperatorGroup) Includes(operator string) bool {
+	return matchesAny(operator, g.Operators)
+}
+
+// NodeThreshold is the number of NotReady nodes of one role the nodes check
+// tolerates: a node count, a percentage of the role's nodes, or both, in which
+// case exceeding either bound exceeds the threshold (e.g. "more than 10% or
+// more than 3 nodes"). The zero value tolerates no NotReady node.
+type NodeThreshold struct {
+	// Count is the number of NotReady nodes tolerated, if HasCount is set.
+	Count    int  `json:"count"`
+	HasCount bool `json:"hasCount"`
+
+	// Percent is the percentage of the role's nodes tolerated, if HasPercent is set.
+	Percent    int  `json:"percent"`
+	HasPercent bool `json:"hasPercent"`
+}
+
+// Exceeded reports whether notReady of total nodes is more than the threshold.
+func (t NodeThreshold) Exceeded(notReady, total int) bool {
+	if !t.HasCount && !t.HasPercent {
+		return notReady > 0
+	}
+	return (t.HasCount && notReady > t.Count) || (t.HasPercent && notReady*100 > t.Percent*total)
+}
+
+// String returns the threshold as written in NODE_NOT_READY_THRESHOLDS.
+func (t NodeThreshold) String() string {
+	switch {
+	case t.HasCount && t.HasPercent:
+		return fmt.Sprintf("%d%%|%d", t.Percent, t.Count)
+	case t.HasPercent:
+		return fmt.Sprintf("%d%%", t.Percent)
+	default:
+		return strconv.Itoa(t.Count)
+	}
+}
+
 // Config holds all runtime configuration for the health-checker.
 type Config struct {
 	// CheckInterval is how often checks are run (default: 30s).
@@ -28,11 +179,185 @@ type Config struct {
 	// Default: [] (empty — the "kube-" prefix in SystemNamespacePrefixes subsumes all
 	// kube-* namespaces). Set this to add non-prefixed system namespaces (e.g., "monitoring").
 	SystemNamespaces []string
+
+	// CheckTimeout is the default deadline for a single check run (default: CheckInterval).
+	// A check that does not finish within its deadline is reported as unknown.
+	CheckTimeout time.Duration
+
+	// CheckTimeouts overrides CheckTimeout for individual checks, keyed by check name.
+	// Default: {} (every check uses CheckTimeout).
+	CheckTimeouts map[string]time.Duration
+
+	// UnknownGaugeMode controls what a check's binary gauge reports when the
+	// check's result is unknown: UnknownGaugeHold (default) or UnknownGaugeFailClosed.
+	UnknownGaugeMode string
+
+	// Hysteresis is how many consecutive results it takes to change a check's
+	// binary gauge (default: 1 unhealthy, 1 healthy, i.e. no hysteresis).
+	Hysteresis Hysteresis
+
+	// CheckHysteresis overrides Hysteresis for individual checks, keyed by check name.
+	// Default: {} (every check uses Hysteresis).
+	CheckHysteresis map[string]Hysteresis
+
+	// WatchMode enables informer-based watch mode: checks read from shared
+	// informer caches and are re-run (debounced) whenever a watched object
+	// changes, in addition to every CheckInterval. Default: false (polling).
+	// Requires the 'watch' verb (deploy/clusterrole-watch.yaml).
+	WatchMode bool
+
+	// WatchDebounce is how long to wait after the first change in watch mode
+	// before re-running checks, so bursts of changes trigger one cycle (default: 5s).
+	WatchDebounce time.Duration
+
+	// LeaderElection enables Lease-based leader election so several replicas can
+	// run: only the leader runs checks, followers report standby. Default: false.
+	LeaderElection bool
+
+	// LeaderElectionNamespace is the namespace of the Lease object.
+	// Default: POD_NAMESPACE, falling back to the pod's service account namespace.
+	LeaderElectionNamespace string
+
+	// LeaderElectionLeaseName is the name of the Lease object (default: "health-checker").
+	LeaderElectionLeaseName string
+
+	// LeaderElectionLeaseDuration is how long a leader's Lease is valid without
+	// renewal (default: 15s). A follower takes over at most this long after the
+	// leader dies, so it must be shorter than CheckInterval.
+	LeaderElectionLeaseDuration time.Duration
+
+	// OperatorConditionMinAge is how long a ClusterOperator must have been
+	// Degraded=True or Available=False, according to the condition's
+	// lastTransitionTime, before the clusteroperators and etcd checks report it.
+	// Default: 0 (reported immediately).
+	OperatorConditionMinAge time.Duration
+
+	// OperatorConditionMinAges overrides OperatorConditionMinAge for individual
+	// ClusterOperators, keyed by operator name. Default: {}.
+	OperatorConditionMinAges map[string]time.Duration
+
+	// OperatorProgressingMaxAge is how long a ClusterOperator may stay
+	// Progressing=True, according to the condition's lastTransitionTime, before
+	// the operatorprogressing check reports it as stuck (default: 1h).
+	OperatorProgressingMaxAge time.Duration
+
+	// OperatorProgressingMaxAges overrides OperatorProgressingMaxAge for
+	// individual ClusterOperators, keyed by operator name. Default: {}.
+	OperatorProgressingMaxAges map[string]time.Duration
+
+	// OperatorGroups are user-defined groups of ClusterOperators, each reported
+	// by its own check (operatorgroup/<name>). Config file only. Default: [].
+	OperatorGroups []OperatorGroup
+
+	// CertCheck enables the certificates check, which needs read access to
+	// Secrets (deploy/clusterrole-certificates.yaml). Default: false.
+	CertCheck bool
+
+	// CertExpiryThreshold is how close to expiry a certificate may get before the
+	// certificates check reports it (default: 7 days).
+	CertExpiryThreshold time.Duration
+
+	// KubeletCertCheck enables probing every node's kubelet serving certificate
+	// (port 10250) in the certificates check. Default: false.
+	KubeletCertCheck bool
+
+	// CSRPendingMaxAge is how long a CertificateSigningRequest may stay pending
+	// before the csrs check reports it (default: 10m).
+	CSRPendingMaxAge time.Duration
+
+	// RestartWindow is the window over which the systempods check counts
+	// container restarts (default: 1h).
+	RestartWindow time.Duration
+
+	// RestartThreshold is the number of restarts of a system container within
+	// RestartWindow at which the systempods check reports it (default: 5).
+	RestartThreshold int
+
+	// PodRules are the global rules of the systempods check.
+	PodRules PodRules
+
+	// NamespacePodRules override PodRules per namespace pattern, applied in
+	// order so that later matching entries win. Config file only. Default: [].
+	NamespacePodRules []NamespacePodRules
+
+	// StuckPodAges is how long a pod in a system namespace may stay in each
+	// state (see DefaultStuckPodAges) before the stuckpods check reports it.
+	// Default: DefaultStuckPodAges.
+	StuckPodAges map[string]time.Duration
+
+	// NodeNotReadyThresholds is the number of NotReady nodes tolerated per node
+	// role ("control-plane", "infra", "worker" or a custom role) before the
+	// nodes check reports the role. Default: {} (no NotReady node is tolerated).
+	NodeNotReadyThresholds map[string]NodeThreshold
+
+	// NodeProblems is the list of node problems (see NodeProblemNames) the
+	// nodeproblems check reports. Default: all of NodeProblemNames.
+	NodeProblems []string
+
+	// NodeProblemTaints is the list of taints the nodeproblems check reports,
+	// each "key" (any effect) or "key:Effect". Default: [] (none).
+	NodeProblemTaints []string
+
+	// DisabledChecks is the list of check names that should not be run.
+	// Default: [] (all registered checks run).
+	DisabledChecks []string
+
+	// ConfigFile is the path of an optional YAML or JSON config file whose values
+	// take precedence over the environment variables. It is re-read while running
+	// (see Reloader). Default: "" (environment variables only).
+	ConfigFile string
 }
 
-// Load reads configuration from environment variables and applies defaults.
+// Load reads configuration from environment variables, overlays the config
+// file named by CONFIG_FILE if set, and applies defaults.
 // Returns an error if any value fails validation.
 func Load() (Config, error) {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		return load(nil)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	return load(data)
+}
+
+// load builds the configuration from environment variables overlaid with the
+// config file content data (nil when no config file is used).
+func load(data []byte) (Config, error) {
+	cfg, err := loadEnv()
+	if err != nil {
+		return Config{}, err
+	}
+
+	if data != nil {
+		cfg.ConfigFile = os.Getenv("CONFIG_FILE")
+		if err := applyFile(&cfg, data); err != nil {
+			return Config{}, fmt.Errorf("config file %s: %w", cfg.ConfigFile, err)
+		}
+	}
+
+	// CHECK_TIMEOUT defaults to CHECK_INTERVAL, whichever source set it.
+	// Defaulting to the interval guarantees a cycle never runs longer than the interval.
+	if cfg.CheckTimeout == 0 {
+		cfg.CheckTimeout = cfg.CheckInterval
+	}
+
+	// LEADER_ELECTION_LEASE_DURATION must be shorter than CHECK_INTERVAL so
+	// failover completes within one interval.
+	if cfg.LeaderElection && cfg.LeaderElectionLeaseDuration >= cfg.CheckInterval {
+		return Config{}, fmt.Errorf("LEADER_ELECTION_LEASE_DURATION (%s) must be shorter than CHECK_INTERVAL (%s) so failover completes within one interval",
+			cfg.LeaderElectionLeaseDuration, cfg.CheckInterval)
+	}
+
+	return cfg, nil
+}
+
+// loadEnv reads configuration from environment variables and applies
+// defaults, except for defaults derived from other settings, which load
+// applies after the config file has been overlaid.
+func loadEnv() (Config, error) {
 	cfg := Config{}
 
 	// CHECK_INTERVAL: positive integer seconds, default 30
@@ -78,9 +403,519 @@ func Load() (Config, error) {
 		cfg.SystemNamespaces = splitAndTrim(nsStr)
 	}
 
+	// CHECK_TIMEOUT: positive integer seconds, default CHECK_INTERVAL (applied by load)
+	timeoutStr := os.Getenv("CHECK_TIMEOUT")
+	if timeoutStr != "" {
+		secs, err := strconv.Atoi(timeoutStr)
+		if err != nil || secs <= 0 {
+			return Config{}, fmt.Errorf("CHECK_TIMEOUT must be a positive integer (got %q)", timeoutStr)
+		}
+		cfg.CheckTimeout = time.Duration(secs) * time.Second
+	}
+
+	// CHECK_TIMEOUTS: comma-separated name=seconds pairs, default "" (no overrides)
+	timeouts, err := parseSecondsMap(os.Getenv("CHECK_TIMEOUTS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("CHECK_TIMEOUTS: %w", err)
+	}
+	cfg.CheckTimeouts = timeouts
+
+	// UNKNOWN_GAUGE_MODE: "hold" or "fail-closed", default "hold"
+	switch mode := os.Getenv("UNKNOWN_GAUGE_MODE"); mode {
+	case "":
+		cfg.UnknownGaugeMode = UnknownGaugeHold
+	case UnknownGaugeHold, UnknownGaugeFailClosed:
+		cfg.UnknownGaugeMode = mode
+	default:
+		return Config{}, fmt.Errorf("UNKNOWN_GAUGE_MODE must be %q or %q (got %q)", UnknownGaugeHold, UnknownGaugeFailClosed, mode)
+	}
+
+	// UNHEALTHY_CYCLES, HEALTHY_CYCLES: positive integers, default 1
+	cfg.Hysteresis = Hysteresis{UnhealthyCycles: 1, HealthyCycles: 1}
+	for name, target := range map[string]*int{
+		"UNHEALTHY_CYCLES": &cfg.Hysteresis.UnhealthyCycles,
+		"HEALTHY_CYCLES":   &cfg.Hysteresis.HealthyCycles,
+	} {
+		if s := os.Getenv(name); s != "" {
+			n, err := strconv.Atoi(s)
+			if err != nil || n <= 0 {
+				return Config{}, fmt.Errorf("%s must be a positive integer (got %q)", name, s)
+			}
+			*target = n
+		}
+	}
+
+	// CHECK_HYSTERESIS: comma-separated name=unhealthy:healthy pairs, default "" (no overrides)
+	hysteresis, err := parseHysteresisMap(os.Getenv("CHECK_HYSTERESIS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("CHECK_HYSTERESIS: %w", err)
+	}
+	cfg.CheckHysteresis = hysteresis
+
+	// WATCH_MODE: boolean, default false
+	watchStr := os.Getenv("WATCH_MODE")
+	if watchStr != "" {
+		watch, err := strconv.ParseBool(watchStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("WATCH_MODE must be true or false (got %q)", watchStr)
+		}
+		cfg.WatchMode = watch
+	}
+
+	// WATCH_DEBOUNCE: positive integer seconds, default 5
+	debounceStr := os.Getenv("WATCH_DEBOUNCE")
+	if debounceStr == "" {
+		cfg.WatchDebounce = 5 * time.Second
+	} else {
+		secs, err := strconv.Atoi(debounceStr)
+		if err != nil || secs <= 0 {
+			return Config{}, fmt.Errorf("WATCH_DEBOUNCE must be a positive integer (got %q)", debounceStr)
+		}
+		cfg.WatchDebounce = time.Duration(secs) * time.Second
+	}
+
+	// LEADER_ELECTION: boolean, default false
+	leStr := os.Getenv("LEADER_ELECTION")
+	if leStr != "" {
+		le, err := strconv.ParseBool(leStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("LEADER_ELECTION must be true or false (got %q)", leStr)
+		}
+		cfg.LeaderElection = le
+	}
+
+	// LEADER_ELECTION_NAMESPACE: default POD_NAMESPACE (resolved further at startup if empty)
+	cfg.LeaderElectionNamespace = os.Getenv("LEADER_ELECTION_NAMESPACE")
+	if cfg.LeaderElectionNamespace == "" {
+		cfg.LeaderElectionNamespace = os.Getenv("POD_NAMESPACE")
+	}
+
+	// LEADER_ELECTION_LEASE_NAME: default "health-checker"
+	cfg.LeaderElectionLeaseName = os.Getenv("LEADER_ELECTION_LEASE_NAME")
+	if cfg.LeaderElectionLeaseName == "" {
+		cfg.LeaderElectionLeaseName = "health-checker"
+	}
+
+	// LEADER_ELECTION_LEASE_DURATION: positive integer seconds, default 15,
+	// must be shorter than CHECK_INTERVAL (validated by load).
+	leaseStr := os.Getenv("LEADER_ELECTION_LEASE_DURATION")
+	if leaseStr == "" {
+		cfg.LeaderElectionLeaseDuration = 15 * time.Second
+	} else {
+		secs, err := strconv.Atoi(leaseStr)
+		if err != nil || secs <= 0 {
+			return Config{}, fmt.Errorf("LEADER_ELECTION_LEASE_DURATION must be a positive integer (got %q)", leaseStr)
+		}
+		cfg.LeaderElectionLeaseDuration = time.Duration(secs) * time.Second
+	}
+
+	// OPERATOR_CONDITION_MIN_AGE: non-negative integer seconds, default 0
+	if s := os.Getenv("OPERATOR_CONDITION_MIN_AGE"); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil || secs < 0 {
+			return Config{}, fmt.Errorf("OPERATOR_CONDITION_MIN_AGE must be a non-negative integer (got %q)", s)
+		}
+		cfg.OperatorConditionMinAge = time.Duration(secs) * time.Second
+	}
+
+	// OPERATOR_CONDITION_MIN_AGES: comma-separated operator=seconds pairs (seconds >= 0), default "" (no overrides)
+	minAges, err := parseNonNegativeSecondsMap(os.Getenv("OPERATOR_CONDITION_MIN_AGES"))
+	if err != nil {
+		return Config{}, fmt.Errorf("OPERATOR_CONDITION_MIN_AGES: %w", err)
+	}
+	cfg.OperatorConditionMinAges = minAges
+
+	// OPERATOR_PROGRESSING_MAX_AGE: positive integer seconds, default 3600
+	cfg.OperatorProgressingMaxAge = time.Hour
+	if s := os.Getenv("OPERATOR_PROGRESSING_MAX_AGE"); s != "" {
+		secs, err := strconv.Atoi(s)
+		if err != nil || secs <= 0 {
+			return Config{}, fmt.Errorf("OPERATOR_PROGRESSING_MAX_AGE must be a positive integer (got %q)", s)
+		}
+		cfg.OperatorProgressingMaxAge = time.Duration(secs) * time.Second
+	}
+
+	// OPERATOR_PROGRESSING_MAX_AGES: comma-separated operator=seconds pairs, default "" (no overrides)
+	maxAges, err := parseSecondsMap(os.Getenv("OPERATOR_PROGRESSING_MAX_AGES"))
+	if err != nil {
+		return Config{}, fmt.Errorf("OPERATOR_PROGRESSING_MAX_AGES: %w", err)
+	}
+	cfg.OperatorProgressingMaxAges = maxAges
+
+	// CERT_CHECK: boolean, default false
+	certStr := os.Getenv("CERT_CHECK")
+	if certStr != "" {
+		certCheck, err := strconv.ParseBool(certStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("CERT_CHECK must be true or false (got %q)", certStr)
+		}
+		cfg.CertCheck = certCheck
+	}
+
+	// CERT_EXPIRY_THRESHOLD_DAYS: positive integer days, default 7
+	thresholdStr := os.Getenv("CERT_EXPIRY_THRESHOLD_DAYS")
+	if thresholdStr == "" {
+		cfg.CertExpiryThreshold = 7 * 24 * time.Hour
+	} else {
+		days, err := strconv.Atoi(thresholdStr)
+		if err != nil || days <= 0 {
+			return Config{}, fmt.Errorf("CERT_EXPIRY_THRESHOLD_DAYS must be a positive integer (got %q)", thresholdStr)
+		}
+		cfg.CertExpiryThreshold = time.Duration(days) * 24 * time.Hour
+	}
+
+	// CERT_CHECK_KUBELET: boolean, default false
+	kubeletStr := os.Getenv("CERT_CHECK_KUBELET")
+	if kubeletStr != "" {
+		kubelet, err := strconv.ParseBool(kubeletStr)
+		if err != nil {
+			return Config{}, fmt.Errorf("CERT_CHECK_KUBELET must be true or false (got %q)", kubeletStr)
+		}
+		cfg.KubeletCertCheck = kubelet
+	}
+
+	// CSR_PENDING_MAX_AGE: positive integer seconds, default 600
+	csrAgeStr := os.Getenv("CSR_PENDING_MAX_AGE")
+	if csrAgeStr == "" {
+		cfg.CSRPendingMaxAge = 10 * time.Minute
+	} else {
+		secs, err := strconv.Atoi(csrAgeStr)
+		if err != nil || secs <= 0 {
+			return Config{}, fmt.Errorf("CSR_PENDING_MAX_AGE must be a positive integer (got %q)", csrAgeStr)
+		}
+		cfg.CSRPendingMaxAge = time.Duration(secs) * time.Second
+	}
+
+	// FATAL_CONTAINER_REASONS: comma-separated, default "CrashLoopBackOff,OOMKilled,Error"
+	cfg.PodRules.FatalReasons = splitAndTrim(os.Getenv("FATAL_CONTAINER_REASONS"))
+	if len(cfg.PodRules.FatalReasons) == 0 {
+		cfg.PodRules.FatalReasons = append([]string(nil), DefaultFatalContainerReasons...)
+	}
+
+	// FAILED_JOB_PODS: "report" or "ignore", default "report"
+	switch mode := os.Getenv("FAILED_JOB_PODS"); mode {
+	case "":
+		cfg.PodRules.FailedJobPods = FailedJobPodsReport
+	case FailedJobPodsReport, FailedJobPodsIgnore:
+		cfg.PodRules.FailedJobPods = mode
+	default:
+		return Config{}, fmt.Errorf("FAILED_JOB_PODS must be %q or %q (got %q)", FailedJobPodsReport, FailedJobPodsIgnore, mode)
+	}
+
+	// POD_EXCLUDE_SELECTOR: label selector, default "" (no exclusions)
+	cfg.PodRules.ExcludeSelector = strings.TrimSpace(os.Getenv("POD_EXCLUDE_SELECTOR"))
+	if _, err := labels.Parse(cfg.PodRules.ExcludeSelector); err != nil {
+		return Config{}, fmt.Errorf("POD_EXCLUDE_SELECTOR: %w", err)
+	}
+
+	// POD_EXCLUDE_OWNER_KINDS: comma-separated owner kinds, default "" (no exclusions)
+	cfg.PodRules.ExcludeOwnerKinds = splitAndTrim(os.Getenv("POD_EXCLUDE_OWNER_KINDS"))
+
+	// RESTART_WINDOW: positive integer seconds, default 3600
+	windowStr := os.Getenv("RESTART_WINDOW")
+	if windowStr == "" {
+		cfg.RestartWindow = time.Hour
+	} else {
+		secs, err := strconv.Atoi(windowStr)
+		if err != nil || secs <= 0 {
+			return Config{}, fmt.Errorf("RESTART_WINDOW must be a positive integer (got %q)", windowStr)
+		}
+		cfg.RestartWindow = time.Duration(secs) * time.Second
+	}
+
+	// RESTART_THRESHOLD: positive integer, default 5
+	restartsStr := os.Getenv("RESTART_THRESHOLD")
+	if restartsStr == "" {
+		cfg.RestartThreshold = 5
+	} else {
+		n, err := strconv.Atoi(restartsStr)
+		if err != nil || n <= 0 {
+			return Config{}, fmt.Errorf("RESTART_THRESHOLD must be a positive integer (got %q)", restartsStr)
+		}
+		cfg.RestartThreshold = n
+	}
+
+	// STUCK_POD_AGES: comma-separated state=seconds overrides of DefaultStuckPodAges
+	stuckAges, err := parseSecondsMap(os.Getenv("STUCK_POD_AGES"))
+	if err != nil {
+		return Config{}, fmt.Errorf("STUCK_POD_AGES: %w", err)
+	}
+	cfg.StuckPodAges, err = withStuckPodAges(DefaultStuckPodAges, stuckAges)
+	if err != nil {
+		return Config{}, fmt.Errorf("STUCK_POD_AGES: %w", err)
+	}
+
+	// NODE_NOT_READY_THRESHOLDS: comma-separated role=count or role=percent%
+	// pairs, default "" (no NotReady node is tolerated)
+	thresholds, err := parseNodeThresholds(os.Getenv("NODE_NOT_READY_THRESHOLDS"))
+	if err != nil {
+		return Config{}, fmt.Errorf("NODE_NOT_READY_THRESHOLDS: %w", err)
+	}
+	cfg.NodeNotReadyThresholds = thresholds
+
+	// NODE_PROBLEMS: comma-separated node problems, default all of NodeProblemNames
+	problemsStr := os.Getenv("NODE_PROBLEMS")
+	if problemsStr == "" {
+		cfg.NodeProblems = append([]string(nil), NodeProblemNames...)
+	} else {
+		cfg.NodeProblems = splitAndTrim(problemsStr)
+		if err := validateNodeProblems(cfg.NodeProblems); err != nil {
+			return Config{}, fmt.Errorf("NODE_PROBLEMS: %w", err)
+		}
+	}
+
+	// NODE_PROBLEM_TAINTS: comma-separated key or key:Effect, default "" (none)
+	cfg.NodeProblemTaints = splitAndTrim(os.Getenv("NODE_PROBLEM_TAINTS"))
+	if err := validateTaints(cfg.NodeProblemTaints); err != nil {
+		return Config{}, fmt.Errorf("NODE_PROBLEM_TAINTS: %w", err)
+	}
+
+	// DISABLED_CHECKS: comma-separated check names, default "" (all checks enabled)
+	// Names are validated against the check registry at startup.
+	cfg.DisabledChecks = splitAndTrim(os.Getenv("DISABLED_CHECKS"))
+
 	return cfg, nil
 }
 
+// Disabled returns the names of the checks that should not be run:
+// DisabledChecks, plus the opt-in certificates check unless CertCheck is set.
+func (c Config) Disabled() []string {
+	if c.CertCheck || slices.Contains(c.DisabledChecks, "certificates") {
+		return c.DisabledChecks
+	}
+	return append(slices.Clip(c.DisabledChecks), "certificates")
+}
+
+// TimeoutFor returns the deadline for a single run of the named check.
+func (c Config) TimeoutFor(check string) time.Duration {
+	if d, ok := c.CheckTimeouts[check]; ok {
+		return d
+	}
+	return c.CheckTimeout
+}
+
+// HysteresisFor returns the hysteresis of the named check.
+func (c Config) HysteresisFor(check string) Hysteresis {
+	if h, ok := c.CheckHysteresis[check]; ok {
+		return h
+	}
+	return c.Hysteresis
+}
+
+// OperatorConditionMinAgeFor returns how long the named ClusterOperator must
+// have been degraded or unavailable before it is reported.
+func (c Config) OperatorConditionMinAgeFor(operator string) time.Duration {
+	if d, ok := c.OperatorConditionMinAges[operator]; ok {
+		return d
+	}
+	return c.OperatorConditionMinAge
+}
+
+// OperatorProgressingMaxAgeFor returns how long the named ClusterOperator may
+// stay Progressing=True before it is reported as stuck.
+func (c Config) OperatorProgressingMaxAgeFor(operator string) time.Duration {
+	if d, ok := c.OperatorProgressingMaxAges[operator]; ok {
+		return d
+	}
+	return c.OperatorProgressingMaxAge
+}
+
+// PodRulesFor returns the systempods rules for the namespace: PodRules
+// overlaid with every matching entry of NamespacePodRules, in order.
+func (c Config) PodRulesFor(namespace string) PodRules {
+	rules := c.PodRules
+	for _, nr := range c.NamespacePodRules {
+		if !matchesAny(namespace, nr.Namespaces) {
+			continue
+		}
+		if nr.FatalReasons != nil {
+			rules.FatalReasons = nr.FatalReasons
+		}
+		if nr.FailedJobPods != nil {
+			rules.FailedJobPods = *nr.FailedJobPods
+		}
+		if nr.ExcludeSelector != nil {
+			rules.ExcludeSelector = *nr.ExcludeSelector
+		}
+		if nr.ExcludeOwnerKinds != nil {
+			rules.ExcludeOwnerKinds = nr.ExcludeOwnerKinds
+		}
+	}
+	return rules
+}
+
+// matchesAny reports whether name matches any of the shell patterns.
+// Patterns are validated when the configuration is loaded.
+func matchesAny(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// withStuckPodAges returns a copy of base with overrides applied, rejecting
+// states that are not in DefaultStuckPodAges.
+func withStuckPodAges(base, overrides map[string]time.Duration) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration, len(base))
+	for state, d := range base {
+		result[state] = d
+	}
+	for state, d := range overrides {
+		if _, ok := DefaultStuckPodAges[state]; !ok {
+			known := make([]string, 0, len(DefaultStuckPodAges))
+			for s := range DefaultStuckPodAges {
+				known = append(known, s)
+			}
+			slices.Sort(known)
+			return nil, fmt.Errorf("unknown pod state %q (known: %s)", state, strings.Join(known, ", "))
+		}
+		result[state] = d
+	}
+	return result, nil
+}
+
+// NodeThresholdFor returns the NotReady threshold for the node role.
+// Roles without a configured threshold tolerate no NotReady node.
+func (c Config) NodeThresholdFor(role string) NodeThreshold {
+	return c.NodeNotReadyThresholds[role]
+}
+
+// parseNodeThresholds parses a comma-separated list of role=threshold pairs
+// (e.g. "worker=10%|3,infra=1"; see parseNodeThreshold). "master" is accepted
+// as an alias of "control-plane".
+func parseNodeThresholds(s string) (map[string]NodeThreshold, error) {
+	result := map[string]NodeThreshold{}
+	for _, pair := range splitAndTrim(s) {
+		role, value, ok := strings.Cut(pair, "=")
+		role = strings.TrimSpace(role)
+		if !ok || role == "" {
+			return nil, fmt.Errorf("expected role=count, role=percent%% or role=percent%%|count (got %q)", pair)
+		}
+		threshold, err := parseNodeThreshold(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("value for %q: %w", role, err)
+		}
+		result[NormalizeNodeRole(role)] = threshold
+	}
+	return result, nil
+}
+
+// parseNodeThreshold parses a non-negative count ("3"), a percentage between
+// 0 and 100 ("10%"), or one of each separated by "|" ("10%|3").
+func parseNodeThreshold(s string) (NodeThreshold, error) {
+	var t NodeThreshold
+	for _, bound := range strings.Split(s, "|") {
+		bound = strings.TrimSpace(bound)
+		digits, percent := strings.CutSuffix(bound, "%")
+		n, err := strconv.Atoi(digits)
+		if err != nil || n < 0 || (percent && n > 100) || (percent && t.HasPercent) || (!percent && t.HasCount) {
+			return NodeThreshold{}, fmt.Errorf("must be a non-negative integer, a percentage 0-100%%, or one of each separated by \"|\" (got %q)", s)
+		}
+		if percent {
+			t.Percent, t.HasPercent = n, true
+		} else {
+			t.Count, t.HasCount = n, true
+		}
+	}
+	return t, nil
+}
+
+// NormalizeNodeRole maps the legacy "master" role to "control-plane".
+func NormalizeNodeRole(role string) string {
+	if role == "master" {
+		return "control-plane"
+	}
+	return role
+}
+
+// validateNodeProblems checks that every name is one of NodeProblemNames.
+func validateNodeProblems(names []string) error {
+	for _, name := range names {
+		if !slices.Contains(NodeProblemNames, name) {
+			return fmt.Errorf("unknown node problem %q (known: %s)", name, strings.Join(NodeProblemNames, ", "))
+		}
+	}
+	return nil
+}
+
+// validateTaints checks that every taint is "key" or "key:Effect" with a
+// valid taint effect.
+func validateTaints(taints []string) error {
+	for _, taint := range taints {
+		key, effect, hasEffect := strings.Cut(taint, ":")
+		if key == "" {
+			return fmt.Errorf("taint key must not be empty (got %q)", taint)
+		}
+		if hasEffect {
+			switch effect {
+			case "NoSchedule", "PreferNoSchedule", "NoExecute":
+			default:
+				return fmt.Errorf("taint effect must be NoSchedule, PreferNoSchedule or NoExecute (got %q)", taint)
+			}
+		}
+	}
+	return nil
+}
+
+// parseSecondsMap parses a comma-separated list of name=seconds pairs
+// (e.g. "systempods=60,nodes=10"). Every value must be a positive integer.
+func parseSecondsMap(s string) (map[string]time.Duration, error) {
+	return parseSecondsMapMin(s, 1)
+}
+
+// parseNonNegativeSecondsMap is like parseSecondsMap but also accepts 0
+// (e.g. "etcd=0" to exempt a single operator from a global minimum age).
+func parseNonNegativeSecondsMap(s string) (map[string]time.Duration, error) {
+	return parseSecondsMapMin(s, 0)
+}
+
+// parseSecondsMapMin parses name=seconds pairs whose values are integers of
+// at least minSecs (0 or 1).
+func parseSecondsMapMin(s string, minSecs int) (map[string]time.Duration, error) {
+	kind := "a positive"
+	if minSecs == 0 {
+		kind = "a non-negative"
+	}
+	result := map[string]time.Duration{}
+	for _, pair := range splitAndTrim(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		value = strings.TrimSpace(value)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=seconds (got %q)", pair)
+		}
+		secs, err := strconv.Atoi(value)
+		if err != nil || secs < minSecs {
+			return nil, fmt.Errorf("value for %q must be %s integer (got %q)", name, kind, value)
+		}
+		result[name] = time.Duration(secs) * time.Second
+	}
+	return result, nil
+}
+
+// parseHysteresisMap parses comma-separated name=unhealthy:healthy pairs
+// (e.g. "clusteroperators=3:2") where both counts are positive integers.
+func parseHysteresisMap(s string) (map[string]Hysteresis, error) {
+	result := map[string]Hysteresis{}
+	for _, pair := range splitAndTrim(s) {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("expected name=unhealthy:healthy (got %q)", pair)
+		}
+		unhealthy, healthy, ok := strings.Cut(strings.TrimSpace(value), ":")
+		u, errU := strconv.Atoi(strings.TrimSpace(unhealthy))
+		h, errH := strconv.Atoi(strings.TrimSpace(healthy))
+		if !ok || errU != nil || errH != nil || u <= 0 || h <= 0 {
+			return nil, fmt.Errorf("value for %q must be two positive integers unhealthy:healthy (got %q)", name, value)
+		}
+		result[name] = Hysteresis{UnhealthyCycles: u, HealthyCycles: h}
+	}
+	return result, nil
+}
+
 // splitAndTrim splits a comma-separated string and trims whitespace from each element.
 func splitAndTrim(s string) []string {
 	parts := strings.Split(s, ",")
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"reflect"
 	"testing"
 	"time"
 )
@@ -172,3 +173,559 @@ func TestLoad_CustomSystemNamespaces(t *testing.T) {
 		t.Error("expected 'monitoring' in SystemNamespaces")
 	}
 }
+
+func TestLoad_DisabledChecks(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "")
+	t.Setenv("METRICS_PORT", "")
+	t.Setenv("SYSTEM_NAMESPACE_PREFIXES", "")
+	t.Setenv("SYSTEM_NAMESPACES", "")
+	t.Setenv("DISABLED_CHECKS", "etcd, systempods")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if len(cfg.DisabledChecks) != 2 || cfg.DisabledChecks[0] != "etcd" || cfg.DisabledChecks[1] != "systempods" {
+		t.Errorf("expected DisabledChecks=[etcd systempods], got %v", cfg.DisabledChecks)
+	}
+}
+
+func TestLoad_CheckTimeoutDefaultsToInterval(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "45")
+	t.Setenv("CHECK_TIMEOUT", "")
+	t.Setenv("CHECK_TIMEOUTS", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CheckTimeout != 45*time.Second {
+		t.Errorf("expected CheckTimeout=45s, got %v", cfg.CheckTimeout)
+	}
+	if got := cfg.TimeoutFor("nodes"); got != 45*time.Second {
+		t.Errorf("expected TimeoutFor(nodes)=45s, got %v", got)
+	}
+}
+
+func TestLoad_CheckTimeouts(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "")
+	t.Setenv("CHECK_TIMEOUT", "10")
+	t.Setenv("CHECK_TIMEOUTS", "systempods=25, nodes=5")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := cfg.TimeoutFor("systempods"); got != 25*time.Second {
+		t.Errorf("expected TimeoutFor(systempods)=25s, got %v", got)
+	}
+	if got := cfg.TimeoutFor("nodes"); got != 5*time.Second {
+		t.Errorf("expected TimeoutFor(nodes)=5s, got %v", got)
+	}
+	if got := cfg.TimeoutFor("etcd"); got != 10*time.Second {
+		t.Errorf("expected TimeoutFor(etcd)=10s, got %v", got)
+	}
+}
+
+func TestLoad_Hysteresis(t *testing.T) {
+	t.Setenv("UNHEALTHY_CYCLES", "2")
+	t.Setenv("HEALTHY_CYCLES", "")
+	t.Setenv("CHECK_HYSTERESIS", "clusteroperators=3:5")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got, want := cfg.HysteresisFor("nodes"), (Hysteresis{UnhealthyCycles: 2, HealthyCycles: 1}); got != want {
+		t.Errorf("expected HysteresisFor(nodes)=%+v, got %+v", want, got)
+	}
+	if got, want := cfg.HysteresisFor("clusteroperators"), (Hysteresis{UnhealthyCycles: 3, HealthyCycles: 5}); got != want {
+		t.Errorf("expected HysteresisFor(clusteroperators)=%+v, got %+v", want, got)
+	}
+}
+
+func TestLoad_InvalidHysteresis(t *testing.T) {
+	cases := []map[string]string{
+		{"UNHEALTHY_CYCLES": "0"},
+		{"HEALTHY_CYCLES": "often"},
+		{"CHECK_HYSTERESIS": "nodes=3"},
+		{"CHECK_HYSTERESIS": "nodes=3:0"},
+		{"CHECK_HYSTERESIS": "=3:1"},
+	}
+	for _, env := range cases {
+		t.Setenv("UNHEALTHY_CYCLES", "")
+		t.Setenv("HEALTHY_CYCLES", "")
+		t.Setenv("CHECK_HYSTERESIS", "")
+		for k, v := range env {
+			t.Setenv(k, v)
+		}
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for %v, got nil", env)
+		}
+	}
+}
+
+func TestLoad_OperatorConditionMinAge(t *testing.T) {
+	t.Setenv("OPERATOR_CONDITION_MIN_AGE", "300")
+	t.Setenv("OPERATOR_CONDITION_MIN_AGES", "etcd=60")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := cfg.OperatorConditionMinAgeFor("etcd"); got != time.Minute {
+		t.Errorf("expected OperatorConditionMinAgeFor(etcd)=1m, got %v", got)
+	}
+	if got := cfg.OperatorConditionMinAgeFor("ingress"); got != 5*time.Minute {
+		t.Errorf("expected OperatorConditionMinAgeFor(ingress)=5m, got %v", got)
+	}
+
+	t.Setenv("OPERATOR_CONDITION_MIN_AGE", "-1")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for OPERATOR_CONDITION_MIN_AGE=-1, got nil")
+	}
+}
+
+func TestLoad_OperatorConditionMinAgesZero(t *testing.T) {
+	t.Setenv("OPERATOR_CONDITION_MIN_AGE", "300")
+	t.Setenv("OPERATOR_CONDITION_MIN_AGES", "etcd=0")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected etcd=0 to be accepted, got: %v", err)
+	}
+	if got := cfg.OperatorConditionMinAgeFor("etcd"); got != 0 {
+		t.Errorf("expected OperatorConditionMinAgeFor(etcd)=0, got %v", got)
+	}
+	if got := cfg.OperatorConditionMinAgeFor("ingress"); got != 5*time.Minute {
+		t.Errorf("expected OperatorConditionMinAgeFor(ingress)=5m, got %v", got)
+	}
+
+	t.Setenv("OPERATOR_CONDITION_MIN_AGES", "etcd=-1")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for OPERATOR_CONDITION_MIN_AGES=etcd=-1, got nil")
+	}
+}
+
+func TestLoad_InvalidCheckTimeout(t *testing.T) {
+	t.Setenv("CHECK_TIMEOUT", "0")
+	t.Setenv("CHECK_TIMEOUTS", "")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for CHECK_TIMEOUT=0, got nil")
+	}
+}
+
+func TestLoad_InvalidCheckTimeouts(t *testing.T) {
+	for _, value := range []string{"nodes", "nodes=abc", "nodes=-1", "=5"} {
+		t.Run(value, func(t *testing.T) {
+			t.Setenv("CHECK_TIMEOUT", "")
+			t.Setenv("CHECK_TIMEOUTS", value)
+
+			_, err := Load()
+			if err == nil {
+				t.Fatalf("expected error for CHECK_TIMEOUTS=%q, got nil", value)
+			}
+		})
+	}
+}
+
+func TestLoad_UnknownGaugeMode(t *testing.T) {
+	t.Setenv("UNKNOWN_GAUGE_MODE", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.UnknownGaugeMode != UnknownGaugeHold {
+		t.Errorf("expected default UnknownGaugeMode=%q, got %q", UnknownGaugeHold, cfg.UnknownGaugeMode)
+	}
+
+	t.Setenv("UNKNOWN_GAUGE_MODE", "fail-closed")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.UnknownGaugeMode != UnknownGaugeFailClosed {
+		t.Errorf("expected UnknownGaugeMode=%q, got %q", UnknownGaugeFailClosed, cfg.UnknownGaugeMode)
+	}
+}
+
+func TestLoad_InvalidUnknownGaugeMode(t *testing.T) {
+	t.Setenv("UNKNOWN_GAUGE_MODE", "ignore")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid UNKNOWN_GAUGE_MODE, got nil")
+	}
+}
+
+func TestLoad_WatchModeDefaults(t *testing.T) {
+	t.Setenv("WATCH_MODE", "")
+	t.Setenv("WATCH_DEBOUNCE", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.WatchMode {
+		t.Error("expected WatchMode=false by default")
+	}
+	if cfg.WatchDebounce != 5*time.Second {
+		t.Errorf("expected WatchDebounce=5s, got %v", cfg.WatchDebounce)
+	}
+}
+
+func TestLoad_WatchMode(t *testing.T) {
+	t.Setenv("WATCH_MODE", "true")
+	t.Setenv("WATCH_DEBOUNCE", "2")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.WatchMode {
+		t.Error("expected WatchMode=true")
+	}
+	if cfg.WatchDebounce != 2*time.Second {
+		t.Errorf("expected WatchDebounce=2s, got %v", cfg.WatchDebounce)
+	}
+}
+
+func TestLoad_InvalidWatchMode(t *testing.T) {
+	t.Setenv("WATCH_MODE", "sometimes")
+	t.Setenv("WATCH_DEBOUNCE", "")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for invalid WATCH_MODE, got nil")
+	}
+}
+
+func TestLoad_LeaderElectionDefaults(t *testing.T) {
+	t.Setenv("LEADER_ELECTION", "")
+	t.Setenv("LEADER_ELECTION_NAMESPACE", "")
+	t.Setenv("LEADER_ELECTION_LEASE_NAME", "")
+	t.Setenv("LEADER_ELECTION_LEASE_DURATION", "")
+	t.Setenv("POD_NAMESPACE", "openshift-health-checker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.LeaderElection {
+		t.Error("expected LeaderElection=false by default")
+	}
+	if cfg.LeaderElectionNamespace != "openshift-health-checker" {
+		t.Errorf("expected LeaderElectionNamespace to default to POD_NAMESPACE, got %q", cfg.LeaderElectionNamespace)
+	}
+	if cfg.LeaderElectionLeaseName != "health-checker" {
+		t.Errorf("expected LeaderElectionLeaseName=health-checker, got %q", cfg.LeaderElectionLeaseName)
+	}
+	if cfg.LeaderElectionLeaseDuration != 15*time.Second {
+		t.Errorf("expected LeaderElectionLeaseDuration=15s, got %v", cfg.LeaderElectionLeaseDuration)
+	}
+}
+
+func TestLoad_LeaderElection(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "60")
+	t.Setenv("LEADER_ELECTION", "true")
+	t.Setenv("LEADER_ELECTION_NAMESPACE", "monitoring")
+	t.Setenv("LEADER_ELECTION_LEASE_NAME", "checker-lock")
+	t.Setenv("LEADER_ELECTION_LEASE_DURATION", "20")
+	t.Setenv("POD_NAMESPACE", "openshift-health-checker")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !cfg.LeaderElection {
+		t.Error("expected LeaderElection=true")
+	}
+	if cfg.LeaderElectionNamespace != "monitoring" {
+		t.Errorf("expected LEADER_ELECTION_NAMESPACE to take precedence, got %q", cfg.LeaderElectionNamespace)
+	}
+	if cfg.LeaderElectionLeaseName != "checker-lock" {
+		t.Errorf("expected LeaderElectionLeaseName=checker-lock, got %q", cfg.LeaderElectionLeaseName)
+	}
+	if cfg.LeaderElectionLeaseDuration != 20*time.Second {
+		t.Errorf("expected LeaderElectionLeaseDuration=20s, got %v", cfg.LeaderElectionLeaseDuration)
+	}
+}
+
+func TestLoad_LeaseDurationNotShorterThanInterval(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "15")
+	t.Setenv("LEADER_ELECTION", "true")
+	t.Setenv("LEADER_ELECTION_LEASE_DURATION", "15")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for LEADER_ELECTION_LEASE_DURATION >= CHECK_INTERVAL, got nil")
+	}
+}
+
+func TestLoad_CertificateDefaults(t *testing.T) {
+	t.Setenv("CERT_CHECK", "")
+	t.Setenv("CERT_EXPIRY_THRESHOLD_DAYS", "")
+	t.Setenv("CERT_CHECK_KUBELET", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CertExpiryThreshold != 7*24*time.Hour {
+		t.Errorf("expected CertExpiryThreshold=168h, got %v", cfg.CertExpiryThreshold)
+	}
+	if cfg.KubeletCertCheck {
+		t.Error("expected KubeletCertCheck=false by default")
+	}
+	if cfg.CertCheck {
+		t.Error("expected CertCheck=false by default")
+	}
+}
+
+func TestConfig_DisabledCertificatesOptIn(t *testing.T) {
+	cfg := Config{DisabledChecks: []string{"etcd"}}
+	if got := cfg.Disabled(); !reflect.DeepEqual(got, []string{"etcd", "certificates"}) {
+		t.Errorf("expected the certificates check to be disabled by default, got %v", got)
+	}
+	if !reflect.DeepEqual(cfg.DisabledChecks, []string{"etcd"}) {
+		t.Errorf("expected DisabledChecks to be unchanged, got %v", cfg.DisabledChecks)
+	}
+
+	cfg.CertCheck = true
+	if got := cfg.Disabled(); !reflect.DeepEqual(got, []string{"etcd"}) {
+		t.Errorf("expected CertCheck to enable the certificates check, got %v", got)
+	}
+}
+
+func TestLoad_CertificateSettings(t *testing.T) {
+	t.Setenv("CERT_EXPIRY_THRESHOLD_DAYS", "30")
+	t.Setenv("CERT_CHECK", "true")
+	t.Setenv("CERT_CHECK_KUBELET", "true")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CertExpiryThreshold != 30*24*time.Hour {
+		t.Errorf("expected CertExpiryThreshold=720h, got %v", cfg.CertExpiryThreshold)
+	}
+	if !cfg.CertCheck {
+		t.Error("expected CertCheck=true")
+	}
+	if !cfg.KubeletCertCheck {
+		t.Error("expected KubeletCertCheck=true")
+	}
+}
+
+func TestLoad_InvalidCertExpiryThreshold(t *testing.T) {
+	t.Setenv("CERT_EXPIRY_THRESHOLD_DAYS", "0")
+
+	_, err := Load()
+	if err == nil {
+		t.Fatal("expected error for CERT_EXPIRY_THRESHOLD_DAYS=0, got nil")
+	}
+}
+
+func TestLoad_OperatorProgressingMaxAge(t *testing.T) {
+	t.Setenv("OPERATOR_PROGRESSING_MAX_AGE", "")
+	t.Setenv("OPERATOR_PROGRESSING_MAX_AGES", "machine-config=7200")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if got := cfg.OperatorProgressingMaxAgeFor("ingress"); got != time.Hour {
+		t.Errorf("expected default OperatorProgressingMaxAgeFor(ingress)=1h, got %v", got)
+	}
+	if got := cfg.OperatorProgressingMaxAgeFor("machine-config"); got != 2*time.Hour {
+		t.Errorf("expected OperatorProgressingMaxAgeFor(machine-config)=2h, got %v", got)
+	}
+
+	t.Setenv("OPERATOR_PROGRESSING_MAX_AGE", "0")
+	if _, err := Load(); err == nil {
+		t.Error("expected error for OPERATOR_PROGRESSING_MAX_AGE=0, got nil")
+	}
+}
+
+func TestLoad_CSRPendingMaxAge(t *testing.T) {
+	t.Setenv("CSR_PENDING_MAX_AGE", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CSRPendingMaxAge != 10*time.Minute {
+		t.Errorf("expected CSRPendingMaxAge=10m, got %v", cfg.CSRPendingMaxAge)
+	}
+
+	t.Setenv("CSR_PENDING_MAX_AGE", "3600")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CSRPendingMaxAge != time.Hour {
+		t.Errorf("expected CSRPendingMaxAge=1h, got %v", cfg.CSRPendingMaxAge)
+	}
+
+	t.Setenv("CSR_PENDING_MAX_AGE", "-1")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for CSR_PENDING_MAX_AGE=-1, got nil")
+	}
+}
+
+func TestLoad_NodeProblems(t *testing.T) {
+	t.Setenv("NODE_PROBLEMS", "")
+	t.Setenv("NODE_PROBLEM_TAINTS", "example.com/maintenance, node.kubernetes.io/unreachable:NoExecute")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.NodeProblems, NodeProblemNames) {
+		t.Errorf("expected NodeProblems=%v by default, got %v", NodeProblemNames, cfg.NodeProblems)
+	}
+	if want := []string{"example.com/maintenance", "node.kubernetes.io/unreachable:NoExecute"}; !reflect.DeepEqual(cfg.NodeProblemTaints, want) {
+		t.Errorf("expected NodeProblemTaints=%v, got %v", want, cfg.NodeProblemTaints)
+	}
+}
+
+func TestLoad_InvalidNodeProblems(t *testing.T) {
+	for name, env := range map[string][2]string{
+		"unknown problem": {"MemoryPressure,Hungry", ""},
+		"invalid effect":  {"", "example.com/maintenance:Sometimes"},
+		"empty key":       {"", ":NoSchedule"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Setenv("NODE_PROBLEMS", env[0])
+			t.Setenv("NODE_PROBLEM_TAINTS", env[1])
+			if _, err := Load(); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestLoad_NodeNotReadyThresholds(t *testing.T) {
+	t.Setenv("NODE_NOT_READY_THRESHOLDS", "worker=10%, infra=1, master=0, gpu=25%|3")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := map[string]NodeThreshold{
+		"worker":        {Percent: 10, HasPercent: true},
+		"infra":         {Count: 1, HasCount: true},
+		"control-plane": {Count: 0, HasCount: true},
+		"gpu":           {Count: 3, HasCount: true, Percent: 25, HasPercent: true},
+	}
+	if !reflect.DeepEqual(cfg.NodeNotReadyThresholds, want) {
+		t.Errorf("expected %v, got %v", want, cfg.NodeNotReadyThresholds)
+	}
+	if got := cfg.NodeThresholdFor("storage"); got != (NodeThreshold{}) {
+		t.Errorf("expected an unconfigured role to tolerate no NotReady node, got %v", got)
+	}
+
+	for _, invalid := range []string{"worker", "worker=-1", "worker=101%", "=3", "worker=ten", "worker=10%|20%", "worker=3|4", "worker=10%|"} {
+		t.Setenv("NODE_NOT_READY_THRESHOLDS", invalid)
+		if _, err := Load(); err == nil {
+			t.Errorf("expected error for NODE_NOT_READY_THRESHOLDS=%q, got nil", invalid)
+		}
+	}
+}
+
+func TestNodeThreshold_Exceeded(t *testing.T) {
+	for _, tc := range []struct {
+		threshold       NodeThreshold
+		notReady, total int
+		want            bool
+	}{
+		{NodeThreshold{}, 0, 3, false},
+		{NodeThreshold{}, 1, 3, true},
+		{NodeThreshold{Count: 2, HasCount: true}, 2, 150, false},
+		{NodeThreshold{Count: 2, HasCount: true}, 3, 150, true},
+		{NodeThreshold{Percent: 10, HasPercent: true}, 15, 150, false},
+		{NodeThreshold{Percent: 10, HasPercent: true}, 16, 150, true},
+		// Either bound is enough: more than 10% or more than 3 nodes.
+		{NodeThreshold{Percent: 10, HasPercent: true, Count: 3, HasCount: true}, 3, 150, false},
+		{NodeThreshold{Percent: 10, HasPercent: true, Count: 3, HasCount: true}, 4, 150, true},
+		{NodeThreshold{Percent: 10, HasPercent: true, Count: 3, HasCount: true}, 2, 10, true},
+	} {
+		if got := tc.threshold.Exceeded(tc.notReady, tc.total); got != tc.want {
+			t.Errorf("%s with %d of %d NotReady: expected %v, got %v", tc.threshold, tc.notReady, tc.total, tc.want, got)
+		}
+	}
+}
+
+func TestLoad_StuckPodAges(t *testing.T) {
+	t.Setenv("STUCK_POD_AGES", "ContainerCreating=300")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.StuckPodAges["ContainerCreating"] != 5*time.Minute {
+		t.Errorf("expected ContainerCreating=5m, got %v", cfg.StuckPodAges["ContainerCreating"])
+	}
+	if cfg.StuckPodAges["Unschedulable"] != DefaultStuckPodAges["Unschedulable"] {
+		t.Errorf("expected Unschedulable to keep its default, got %v", cfg.StuckPodAges["Unschedulable"])
+	}
+	if DefaultStuckPodAges["ContainerCreating"] != 10*time.Minute {
+		t.Error("expected the override not to modify DefaultStuckPodAges")
+	}
+
+	t.Setenv("STUCK_POD_AGES", "Running=60")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an unknown pod state, got nil")
+	}
+}
+
+func TestLoad_RestartSettings(t *testing.T) {
+	t.Setenv("RESTART_WINDOW", "")
+	t.Setenv("RESTART_THRESHOLD", "")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.RestartWindow != time.Hour || cfg.RestartThreshold != 5 {
+		t.Errorf("expected 5 restarts per 1h by default, got %d per %v", cfg.RestartThreshold, cfg.RestartWindow)
+	}
+
+	t.Setenv("RESTART_THRESHOLD", "0")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for RESTART_THRESHOLD=0, got nil")
+	}
+}
+
+func TestLoad_PodRules(t *testing.T) {
+	t.Setenv("FATAL_CONTAINER_REASONS", "")
+	t.Setenv("FAILED_JOB_PODS", "")
+	t.Setenv("POD_EXCLUDE_SELECTOR", "app in (installer, pruner)")
+	t.Setenv("POD_EXCLUDE_OWNER_KINDS", "Job")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if !reflect.DeepEqual(cfg.PodRules.FatalReasons, DefaultFatalContainerReasons) {
+		t.Errorf("expected default fatal reasons, got %v", cfg.PodRules.FatalReasons)
+	}
+	if cfg.PodRules.FailedJobPods != FailedJobPodsReport {
+		t.Errorf("expected FailedJobPods=report by default, got %q", cfg.PodRules.FailedJobPods)
+	}
+	if cfg.PodRules.ExcludeSelector != "app in (installer, pruner)" {
+		t.Errorf("unexpected ExcludeSelector %q", cfg.PodRules.ExcludeSelector)
+	}
+
+	t.Setenv("POD_EXCLUDE_SELECTOR", "app in (")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for an invalid POD_EXCLUDE_SELECTOR, got nil")
+	}
+	t.Setenv("POD_EXCLUDE_SELECTOR", "")
+	t.Setenv("FAILED_JOB_PODS", "sometimes")
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for FAILED_JOB_PODS=sometimes, got nil")
+	}
+}
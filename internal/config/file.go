@@ -0,0 +1,536 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"sigs.k8s.io/yaml"
+)
+
+// Duration is a config file duration. It accepts either a Go duration string
+// ("90s", "5m") or a number of seconds, matching the environment variables.
+type Duration time.Duration
+
+// UnmarshalJSON implements json.Unmarshaler. YAML input is converted to JSON
+// before decoding, so this also covers YAML files.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var secs int
+	if err := json.Unmarshal(data, &secs); err == nil {
+		*d = Duration(time.Duration(secs) * time.Second)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a number of seconds or a string such as \"90s\" (got %s)", data)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("invalid duration %q: %w", s, err)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// fileConfig is the schema of the config file. Every field is optional: a
+// field that is absent leaves the value from the environment variables (or
+// its default) in place.
+type fileConfig struct {
+	CheckInterval              *Duration                `json:"checkInterval"`
+	MetricsPort                *int                     `json:"metricsPort"`
+	SystemNamespacePrefixes    *[]string                `json:"systemNamespacePrefixes"`
+	SystemNamespaces           *[]string                `json:"systemNamespaces"`
+	CheckTimeout               *Duration                `json:"checkTimeout"`
+	UnknownGaugeMode           *string                  `json:"unknownGaugeMode"`
+	UnhealthyCycles            *int                     `json:"unhealthyCycles"`
+	HealthyCycles              *int                     `json:"healthyCycles"`
+	WatchMode                  *bool                    `json:"watchMode"`
+	WatchDebounce              *Duration                `json:"watchDebounce"`
+	LeaderElection             *fileLeaderElection      `json:"leaderElection"`
+	OperatorConditionMinAge    *Duration                `json:"operatorConditionMinAge"`
+	OperatorConditionMinAges   map[string]Duration      `json:"operatorConditionMinAges"`
+	OperatorProgressingMaxAge  *Duration                `json:"operatorProgressingMaxAge"`
+	OperatorProgressingMaxAges map[string]Duration      `json:"operatorProgressingMaxAges"`
+	OperatorGroups             []fileOperatorGroup      `json:"operatorGroups"`
+	CertCheck                  *bool                    `json:"certCheck"`
+	CertExpiryThreshold        *Duration                `json:"certExpiryThreshold"`
+	KubeletCertCheck           *bool                    `json:"kubeletCertCheck"`
+	CSRPendingMaxAge           *Duration                `json:"csrPendingMaxAge"`
+	FatalContainerReasons      *[]string                `json:"fatalContainerReasons"`
+	FailedJobPods              *string                  `json:"failedJobPods"`
+	PodExcludeSelector         *string                  `json:"podExcludeSelector"`
+	PodExcludeOwnerKinds       *[]string                `json:"podExcludeOwnerKinds"`
+	NamespacePodRules          []fileNamespacePodRules  `json:"namespacePodRules"`
+	RestartWindow              *Duration                `json:"restartWindow"`
+	RestartThreshold           *int                     `json:"restartThreshold"`
+	StuckPodAges               map[string]Duration      `json:"stuckPodAges"`
+	NodeNotReadyThresholds     map[string]fileThreshold `json:"nodeNotReadyThresholds"`
+	NodeProblems               *[]string                `json:"nodeProblems"`
+	NodeProblemTaints          *[]string                `json:"nodeProblemTaints"`
+	DisabledChecks             *[]string                `json:"disabledChecks"`
+	Checks                     map[string]fileCheck     `json:"checks"`
+}
+
+// fileOperatorGroup holds an OperatorGroup.
+type fileOperatorGroup struct {
+	Name      string   `json:"name"`
+	Operators []string `json:"operators"`
+	Severity  string   `json:"severity"`
+}
+
+// fileNamespacePodRules holds a NamespacePodRules entry.
+type fileNamespacePodRules struct {
+	Namespaces            []string  `json:"namespaces"`
+	FatalContainerReasons *[]string `json:"fatalContainerReasons"`
+	FailedJobPods         *string   `json:"failedJobPods"`
+	PodExcludeSelector    *string   `json:"podExcludeSelector"`
+	PodExcludeOwnerKinds  *[]string `json:"podExcludeOwnerKinds"`
+}
+
+// fileThreshold is a NodeThreshold written as a count (3 or "3"), a
+// percentage ("10%") or both ("10%|3").
+type fileThreshold NodeThreshold
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (t *fileThreshold) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		s = string(data)
+	}
+	parsed, err := parseNodeThreshold(s)
+	if err != nil {
+		return err
+	}
+	*t = fileThreshold(parsed)
+	return nil
+}
+
+// fileLeaderElection holds the LEADER_ELECTION* settings.
+type fileLeaderElection struct {
+	Enabled       *bool     `json:"enabled"`
+	Namespace     *string   `json:"namespace"`
+	LeaseName     *string   `json:"leaseName"`
+	LeaseDuration *Duration `json:"leaseDuration"`
+}
+
+// fileCheck holds the settings for a single check, keyed by check name.
+type fileCheck struct {
+	// Enabled false disables the check, true re-enables a check listed in
+	// DISABLED_CHECKS or disabledChecks.
+	Enabled *bool `json:"enabled"`
+
+	// Timeout overrides checkTimeout for this check.
+	Timeout *Duration `json:"timeout"`
+
+	// UnhealthyCycles and HealthyCycles override unhealthyCycles and
+	// healthyCycles for this check.
+	UnhealthyCycles *int `json:"unhealthyCycles"`
+	HealthyCycles   *int `json:"healthyCycles"`
+}
+
+// applyFile overlays the YAML or JSON config file content data onto cfg.
+// Unknown fields are rejected so that typos are not silently ignored, and
+// every value is validated like its environment variable.
+func applyFile(cfg *Config, data []byte) error {
+	var f fileConfig
+	if err := yaml.UnmarshalStrict(data, &f); err != nil {
+		return err
+	}
+
+	if f.CheckInterval != nil {
+		if *f.CheckInterval <= 0 {
+			return fmt.Errorf("checkInterval must be positive (got %s)", time.Duration(*f.CheckInterval))
+		}
+		cfg.CheckInterval = time.Duration(*f.CheckInterval)
+	}
+
+	if f.MetricsPort != nil {
+		if *f.MetricsPort < 1 || *f.MetricsPort > 65535 {
+			return fmt.Errorf("metricsPort must be a valid port number 1-65535 (got %d)", *f.MetricsPort)
+		}
+		cfg.MetricsPort = *f.MetricsPort
+	}
+
+	if f.SystemNamespacePrefixes != nil {
+		cfg.SystemNamespacePrefixes = trimAll(*f.SystemNamespacePrefixes)
+	}
+	if f.SystemNamespaces != nil {
+		cfg.SystemNamespaces = trimAll(*f.SystemNamespaces)
+	}
+
+	if f.CheckTimeout != nil {
+		if *f.CheckTimeout <= 0 {
+			return fmt.Errorf("checkTimeout must be positive (got %s)", time.Duration(*f.CheckTimeout))
+		}
+		cfg.CheckTimeout = time.Duration(*f.CheckTimeout)
+	}
+
+	if f.UnknownGaugeMode != nil {
+		switch mode := *f.UnknownGaugeMode; mode {
+		case UnknownGaugeHold, UnknownGaugeFailClosed:
+			cfg.UnknownGaugeMode = mode
+		default:
+			return fmt.Errorf("unknownGaugeMode must be %q or %q (got %q)", UnknownGaugeHold, UnknownGaugeFailClosed, mode)
+		}
+	}
+
+	if f.UnhealthyCycles != nil {
+		if *f.UnhealthyCycles <= 0 {
+			return fmt.Errorf("unhealthyCycles must be positive (got %d)", *f.UnhealthyCycles)
+		}
+		cfg.Hysteresis.UnhealthyCycles = *f.UnhealthyCycles
+	}
+	if f.HealthyCycles != nil {
+		if *f.HealthyCycles <= 0 {
+			return fmt.Errorf("healthyCycles must be positive (got %d)", *f.HealthyCycles)
+		}
+		cfg.Hysteresis.HealthyCycles = *f.HealthyCycles
+	}
+
+	if f.WatchMode != nil {
+		cfg.WatchMode = *f.WatchMode
+	}
+	if f.WatchDebounce != nil {
+		if *f.WatchDebounce <= 0 {
+			return fmt.Errorf("watchDebounce must be positive (got %s)", time.Duration(*f.WatchDebounce))
+		}
+		cfg.WatchDebounce = time.Duration(*f.WatchDebounce)
+	}
+
+	if le := f.LeaderElection; le != nil {
+		if le.Enabled != nil {
+			cfg.LeaderElection = *le.Enabled
+		}
+		if le.Namespace != nil && *le.Namespace != "" {
+			cfg.LeaderElectionNamespace = *le.Namespace
+		}
+		if le.LeaseName != nil && *le.LeaseName != "" {
+			cfg.LeaderElectionLeaseName = *le.LeaseName
+		}
+		if le.LeaseDuration != nil {
+			if *le.LeaseDuration <= 0 {
+				return fmt.Errorf("leaderElection.leaseDuration must be positive (got %s)", time.Duration(*le.LeaseDuration))
+			}
+			cfg.LeaderElectionLeaseDuration = time.Duration(*le.LeaseDuration)
+		}
+	}
+
+	if f.OperatorConditionMinAge != nil {
+		if *f.OperatorConditionMinAge < 0 {
+			return fmt.Errorf("operatorConditionMinAge must not be negative (got %s)", time.Duration(*f.OperatorConditionMinAge))
+		}
+		cfg.OperatorConditionMinAge = time.Duration(*f.OperatorConditionMinAge)
+	}
+	if f.OperatorConditionMinAges != nil {
+		minAges := make(map[string]time.Duration, len(f.OperatorConditionMinAges))
+		for name, d := range f.OperatorConditionMinAges {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("operatorConditionMinAges: operator name must not be empty")
+			}
+			if d < 0 {
+				return fmt.Errorf("operatorConditionMinAges.%s must not be negative (got %s)", name, time.Duration(d))
+			}
+			minAges[name] = time.Duration(d)
+		}
+		cfg.OperatorConditionMinAges = minAges
+	}
+
+	if f.OperatorProgressingMaxAge != nil {
+		if *f.OperatorProgressingMaxAge <= 0 {
+			return fmt.Errorf("operatorProgressingMaxAge must be positive (got %s)", time.Duration(*f.OperatorProgressingMaxAge))
+		}
+		cfg.OperatorProgressingMaxAge = time.Duration(*f.OperatorProgressingMaxAge)
+	}
+	if f.OperatorProgressingMaxAges != nil {
+		maxAges := make(map[string]time.Duration, len(f.OperatorProgressingMaxAges))
+		for name, d := range f.OperatorProgressingMaxAges {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("operatorProgressingMaxAges: operator name must not be empty")
+			}
+			if d <= 0 {
+				return fmt.Errorf("operatorProgressingMaxAges.%s must be positive (got %s)", name, time.Duration(d))
+			}
+			maxAges[name] = time.Duration(d)
+		}
+		cfg.OperatorProgressingMaxAges = maxAges
+	}
+
+	if f.OperatorGroups != nil {
+		groups := make([]OperatorGroup, 0, len(f.OperatorGroups))
+		seen := make(map[string]bool, len(f.OperatorGroups))
+		for i, fg := range f.OperatorGroups {
+			g, err := fg.toGroup()
+			if err != nil {
+				return fmt.Errorf("operatorGroups[%d]: %w", i, err)
+			}
+			if seen[g.Name] {
+				return fmt.Errorf("operatorGroups[%d]: duplicate group name %q", i, g.Name)
+			}
+			seen[g.Name] = true
+			groups = append(groups, g)
+		}
+		cfg.OperatorGroups = groups
+	}
+
+	if f.CertCheck != nil {
+		cfg.CertCheck = *f.CertCheck
+	}
+	if f.CertExpiryThreshold != nil {
+		if *f.CertExpiryThreshold <= 0 {
+			return fmt.Errorf("certExpiryThreshold must be positive (got %s)", time.Duration(*f.CertExpiryThreshold))
+		}
+		cfg.CertExpiryThreshold = time.Duration(*f.CertExpiryThreshold)
+	}
+	if f.KubeletCertCheck != nil {
+		cfg.KubeletCertCheck = *f.KubeletCertCheck
+	}
+
+	if f.CSRPendingMaxAge != nil {
+		if *f.CSRPendingMaxAge <= 0 {
+			return fmt.Errorf("csrPendingMaxAge must be positive (got %s)", time.Duration(*f.CSRPendingMaxAge))
+		}
+		cfg.CSRPendingMaxAge = time.Duration(*f.CSRPendingMaxAge)
+	}
+
+	if f.FatalContainerReasons != nil {
+		reasons := trimAll(*f.FatalContainerReasons)
+		if len(reasons) == 0 {
+			return fmt.Errorf("fatalContainerReasons must not be empty")
+		}
+		cfg.PodRules.FatalReasons = reasons
+	}
+	if f.FailedJobPods != nil {
+		if err := validateFailedJobPods(*f.FailedJobPods); err != nil {
+			return fmt.Errorf("failedJobPods: %w", err)
+		}
+		cfg.PodRules.FailedJobPods = *f.FailedJobPods
+	}
+	if f.PodExcludeSelector != nil {
+		if _, err := labels.Parse(*f.PodExcludeSelector); err != nil {
+			return fmt.Errorf("podExcludeSelector: %w", err)
+		}
+		cfg.PodRules.ExcludeSelector = strings.TrimSpace(*f.PodExcludeSelector)
+	}
+	if f.PodExcludeOwnerKinds != nil {
+		cfg.PodRules.ExcludeOwnerKinds = trimAll(*f.PodExcludeOwnerKinds)
+	}
+	if f.NamespacePodRules != nil {
+		rules := make([]NamespacePodRules, 0, len(f.NamespacePodRules))
+		for i, fr := range f.NamespacePodRules {
+			nr, err := fr.toRules()
+			if err != nil {
+				return fmt.Errorf("namespacePodRules[%d]: %w", i, err)
+			}
+			rules = append(rules, nr)
+		}
+		cfg.NamespacePodRules = rules
+	}
+
+	if f.RestartWindow != nil {
+		if *f.RestartWindow <= 0 {
+			return fmt.Errorf("restartWindow must be positive (got %s)", time.Duration(*f.RestartWindow))
+		}
+		cfg.RestartWindow = time.Duration(*f.RestartWindow)
+	}
+	if f.RestartThreshold != nil {
+		if *f.RestartThreshold <= 0 {
+			return fmt.Errorf("restartThreshold must be positive (got %d)", *f.RestartThreshold)
+		}
+		cfg.RestartThreshold = *f.RestartThreshold
+	}
+
+	if f.StuckPodAges != nil {
+		overrides := make(map[string]time.Duration, len(f.StuckPodAges))
+		for state, d := range f.StuckPodAges {
+			if d <= 0 {
+				return fmt.Errorf("stuckPodAges.%s must be positive (got %s)", state, time.Duration(d))
+			}
+			overrides[state] = time.Duration(d)
+		}
+		ages, err := withStuckPodAges(cfg.StuckPodAges, overrides)
+		if err != nil {
+			return fmt.Errorf("stuckPodAges: %w", err)
+		}
+		cfg.StuckPodAges = ages
+	}
+
+	if f.NodeNotReadyThresholds != nil {
+		thresholds := make(map[string]NodeThreshold, len(f.NodeNotReadyThresholds))
+		for role, t := range f.NodeNotReadyThresholds {
+			if strings.TrimSpace(role) == "" {
+				return fmt.Errorf("nodeNotReadyThresholds: role must not be empty")
+			}
+			thresholds[NormalizeNodeRole(role)] = NodeThreshold(t)
+		}
+		cfg.NodeNotReadyThresholds = thresholds
+	}
+
+	if f.NodeProblems != nil {
+		problems := trimAll(*f.NodeProblems)
+		if err := validateNodeProblems(problems); err != nil {
+			return fmt.Errorf("nodeProblems: %w", err)
+		}
+		cfg.NodeProblems = problems
+	}
+	if f.NodeProblemTaints != nil {
+		taints := trimAll(*f.NodeProblemTaints)
+		if err := validateTaints(taints); err != nil {
+			return fmt.Errorf("nodeProblemTaints: %w", err)
+		}
+		cfg.NodeProblemTaints = taints
+	}
+
+	if f.DisabledChecks != nil {
+		cfg.DisabledChecks = trimAll(*f.DisabledChecks)
+	}
+
+	// Per-check settings are applied last so they override the lists above.
+	// Maps are copied rather than modified, so the result never shares state
+	// with a previously loaded Config.
+	timeouts := make(map[string]time.Duration, len(cfg.CheckTimeouts)+len(f.Checks))
+	for name, d := range cfg.CheckTimeouts {
+		timeouts[name] = d
+	}
+	hysteresis := make(map[string]Hysteresis, len(cfg.CheckHysteresis)+len(f.Checks))
+	for name, h := range cfg.CheckHysteresis {
+		hysteresis[name] = h
+	}
+	names := make([]string, 0, len(f.Checks))
+	for name := range f.Checks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		c := f.Checks[name]
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("checks: check name must not be empty")
+		}
+		if c.Timeout != nil {
+			if *c.Timeout <= 0 {
+				return fmt.Errorf("checks.%s.timeout must be positive (got %s)", name, time.Duration(*c.Timeout))
+			}
+			timeouts[name] = time.Duration(*c.Timeout)
+		}
+		if c.UnhealthyCycles != nil || c.HealthyCycles != nil {
+			// A count that is not set is inherited from the global setting.
+			h := cfg.HysteresisFor(name)
+			if c.UnhealthyCycles != nil {
+				if *c.UnhealthyCycles <= 0 {
+					return fmt.Errorf("checks.%s.unhealthyCycles must be positive (got %d)", name, *c.UnhealthyCycles)
+				}
+				h.UnhealthyCycles = *c.UnhealthyCycles
+			}
+			if c.HealthyCycles != nil {
+				if *c.HealthyCycles <= 0 {
+					return fmt.Errorf("checks.%s.healthyCycles must be positive (got %d)", name, *c.HealthyCycles)
+				}
+				h.HealthyCycles = *c.HealthyCycles
+			}
+			hysteresis[name] = h
+		}
+		if c.Enabled != nil {
+			cfg.DisabledChecks = withoutName(cfg.DisabledChecks, name)
+			if !*c.Enabled {
+				cfg.DisabledChecks = append(cfg.DisabledChecks, name)
+			}
+		}
+	}
+	cfg.CheckTimeouts = timeouts
+	cfg.CheckHysteresis = hysteresis
+
+	return nil
+}
+
+// toGroup validates the entry and converts it to an OperatorGroup.
+func (fg fileOperatorGroup) toGroup() (OperatorGroup, error) {
+	g := OperatorGroup{
+		Name:      strings.TrimSpace(fg.Name),
+		Operators: trimAll(fg.Operators),
+		Severity:  strings.TrimSpace(fg.Severity),
+	}
+	if g.Name == "" {
+		return OperatorGroup{}, fmt.Errorf("name must not be empty")
+	}
+	if len(g.Operators) == 0 {
+		return OperatorGroup{}, fmt.Errorf("group %q: operators must not be empty", g.Name)
+	}
+	for _, pattern := range g.Operators {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return OperatorGroup{}, fmt.Errorf("group %q: invalid operator pattern %q: %w", g.Name, pattern, err)
+		}
+	}
+	if g.Severity == "" {
+		g.Severity = DefaultOperatorGroupSeverity
+	}
+	return g, nil
+}
+
+// toRules validates the entry and converts it to a NamespacePodRules.
+func (fr fileNamespacePodRules) toRules() (NamespacePodRules, error) {
+	nr := NamespacePodRules{Namespaces: trimAll(fr.Namespaces)}
+	if len(nr.Namespaces) == 0 {
+		return NamespacePodRules{}, fmt.Errorf("namespaces must not be empty")
+	}
+	for _, pattern := range nr.Namespaces {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return NamespacePodRules{}, fmt.Errorf("invalid namespace pattern %q: %w", pattern, err)
+		}
+	}
+	if fr.FatalContainerReasons != nil {
+		nr.FatalReasons = trimAll(*fr.FatalContainerReasons)
+	}
+	if fr.FailedJobPods != nil {
+		if err := validateFailedJobPods(*fr.FailedJobPods); err != nil {
+			return NamespacePodRules{}, fmt.Errorf("failedJobPods: %w", err)
+		}
+		nr.FailedJobPods = fr.FailedJobPods
+	}
+	if fr.PodExcludeSelector != nil {
+		if _, err := labels.Parse(*fr.PodExcludeSelector); err != nil {
+			return NamespacePodRules{}, fmt.Errorf("podExcludeSelector: %w", err)
+		}
+		selector := strings.TrimSpace(*fr.PodExcludeSelector)
+		nr.ExcludeSelector = &selector
+	}
+	if fr.PodExcludeOwnerKinds != nil {
+		nr.ExcludeOwnerKinds = trimAll(*fr.PodExcludeOwnerKinds)
+	}
+	return nr, nil
+}
+
+// validateFailedJobPods checks that mode is a valid PodRules.FailedJobPods value.
+func validateFailedJobPods(mode string) error {
+	if mode != FailedJobPodsReport && mode != FailedJobPodsIgnore {
+		return fmt.Errorf("must be %q or %q (got %q)", FailedJobPodsReport, FailedJobPodsIgnore, mode)
+	}
+	return nil
+}
+
+// trimAll trims whitespace from each element and drops empty elements, like
+// splitAndTrim does for comma-separated environment variables.
+func trimAll(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// withoutName returns names without any occurrence of name, without
+// modifying names.
+func withoutName(names []string, name string) []string {
+	result := make([]string, 0, len(names))
+	for _, n := range names {
+		if n != name {
+			result = append(result, n)
+		}
+	}
+	return result
+}
@@ -0,0 +1,233 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// writeConfigFile writes content to a temporary config file and points
+// CONFIG_FILE at it.
+func writeConfigFile(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("failed to write config file: %v", err)
+	}
+	t.Setenv("CONFIG_FILE", path)
+	return path
+}
+
+func TestLoad_ConfigFileYAML(t *testing.T) {
+	t.Setenv("CHECK_INTERVAL", "30")
+	t.Setenv("CHECK_TIMEOUT", "")
+	t.Setenv("DISABLED_CHECKS", "etcd")
+	writeConfigFile(t, `
+checkInterval: 1m
+systemNamespaces: [monitoring]
+unknownGaugeMode: fail-closed
+checks:
+  systempods:
+    timeout: 90
+  etcd:
+    enabled: true
+  nodes:
+    enabled: false
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.CheckInterval != time.Minute {
+		t.Errorf("expected the file to override CHECK_INTERVAL, got %v", cfg.CheckInterval)
+	}
+	if cfg.CheckTimeout != time.Minute {
+		t.Errorf("expected CheckTimeout to default to the file's checkInterval, got %v", cfg.CheckTimeout)
+	}
+	if len(cfg.SystemNamespaces) != 1 || cfg.SystemNamespaces[0] != "monitoring" {
+		t.Errorf("expected SystemNamespaces=[monitoring], got %v", cfg.SystemNamespaces)
+	}
+	if cfg.UnknownGaugeMode != UnknownGaugeFailClosed {
+		t.Errorf("expected UnknownGaugeMode=fail-closed, got %q", cfg.UnknownGaugeMode)
+	}
+	if cfg.TimeoutFor("systempods") != 90*time.Second {
+		t.Errorf("expected systempods timeout=90s, got %v", cfg.TimeoutFor("systempods"))
+	}
+	if len(cfg.DisabledChecks) != 1 || cfg.DisabledChecks[0] != "nodes" {
+		t.Errorf("expected per-check settings to re-enable etcd and disable nodes, got %v", cfg.DisabledChecks)
+	}
+}
+
+func TestLoad_ConfigFileJSON(t *testing.T) {
+	writeConfigFile(t, `{"metricsPort": 9090, "watchMode": true, "watchDebounce": "2s"}`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if cfg.MetricsPort != 9090 {
+		t.Errorf("expected MetricsPort=9090, got %d", cfg.MetricsPort)
+	}
+	if !cfg.WatchMode || cfg.WatchDebounce != 2*time.Second {
+		t.Errorf("expected watch mode with 2s debounce, got %v/%v", cfg.WatchMode, cfg.WatchDebounce)
+	}
+}
+
+func TestLoad_ConfigFileNodeThresholds(t *testing.T) {
+	writeConfigFile(t, `
+nodeNotReadyThresholds:
+  master: 0
+  worker: "10%"
+  infra: "2"
+  gpu: "25%|3"
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for role, want := range map[string]NodeThreshold{
+		"control-plane": {Count: 0, HasCount: true},
+		"worker":        {Percent: 10, HasPercent: true},
+		"infra":         {Count: 2, HasCount: true},
+		"gpu":           {Count: 3, HasCount: true, Percent: 25, HasPercent: true},
+	} {
+		if got := cfg.NodeThresholdFor(role); got != want {
+			t.Errorf("role %s: expected %v, got %v", role, want, got)
+		}
+	}
+}
+
+func TestLoad_ConfigFileHysteresis(t *testing.T) {
+	t.Setenv("UNHEALTHY_CYCLES", "")
+	t.Setenv("HEALTHY_CYCLES", "")
+	t.Setenv("CHECK_HYSTERESIS", "etcd=4:4")
+	writeConfigFile(t, `
+unhealthyCycles: 2
+healthyCycles: 3
+checks:
+  clusteroperators: {unhealthyCycles: 5}
+  etcd: {healthyCycles: 1}
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := map[string]Hysteresis{
+		"nodes":            {UnhealthyCycles: 2, HealthyCycles: 3},
+		"clusteroperators": {UnhealthyCycles: 5, HealthyCycles: 3},
+		"etcd":             {UnhealthyCycles: 4, HealthyCycles: 1},
+	}
+	for check, h := range want {
+		if got := cfg.HysteresisFor(check); got != h {
+			t.Errorf("expected HysteresisFor(%s)=%+v, got %+v", check, h, got)
+		}
+	}
+}
+
+func TestLoad_ConfigFileOperatorGroups(t *testing.T) {
+	writeConfigFile(t, `
+operatorGroups:
+  - name: control-plane
+    operators: ["kube-*", etcd]
+    severity: critical
+  - name: ingress
+    operators: [ingress, dns, network]
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	want := []OperatorGroup{
+		{Name: "control-plane", Operators: []string{"kube-*", "etcd"}, Severity: "critical"},
+		{Name: "ingress", Operators: []string{"ingress", "dns", "network"}, Severity: DefaultOperatorGroupSeverity},
+	}
+	if !reflect.DeepEqual(cfg.OperatorGroups, want) {
+		t.Errorf("expected operator groups %+v, got %+v", want, cfg.OperatorGroups)
+	}
+	if !cfg.OperatorGroups[0].Includes("kube-apiserver") || cfg.OperatorGroups[0].Includes("ingress") {
+		t.Error("expected control-plane to include kube-apiserver but not ingress")
+	}
+}
+
+func TestLoad_ConfigFilePodRules(t *testing.T) {
+	t.Setenv("FAILED_JOB_PODS", "ignore")
+	t.Setenv("FATAL_CONTAINER_REASONS", "")
+	writeConfigFile(t, `
+podExcludeOwnerKinds: [Job]
+namespacePodRules:
+  - namespaces: ["openshift-kube-*", openshift-etcd]
+    podExcludeSelector: app=installer
+  - namespaces: [openshift-etcd]
+    failedJobPods: report
+    fatalContainerReasons: [CrashLoopBackOff]
+`)
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	global := cfg.PodRulesFor("openshift-monitoring")
+	if global.FailedJobPods != FailedJobPodsIgnore || global.ExcludeSelector != "" || len(global.FatalReasons) != 3 {
+		t.Errorf("expected the global rules for openshift-monitoring, got %+v", global)
+	}
+	if len(global.ExcludeOwnerKinds) != 1 || global.ExcludeOwnerKinds[0] != "Job" {
+		t.Errorf("expected podExcludeOwnerKinds=[Job], got %v", global.ExcludeOwnerKinds)
+	}
+
+	apiserver := cfg.PodRulesFor("openshift-kube-apiserver")
+	if apiserver.ExcludeSelector != "app=installer" || apiserver.FailedJobPods != FailedJobPodsIgnore {
+		t.Errorf("expected the first rule to apply to openshift-kube-apiserver, got %+v", apiserver)
+	}
+
+	etcd := cfg.PodRulesFor("openshift-etcd")
+	if etcd.ExcludeSelector != "app=installer" || etcd.FailedJobPods != FailedJobPodsReport || len(etcd.FatalReasons) != 1 {
+		t.Errorf("expected both rules to apply to openshift-etcd, later ones winning, got %+v", etcd)
+	}
+}
+
+func TestLoad_ConfigFileInvalid(t *testing.T) {
+	cases := map[string]string{
+		"unknown field":       "checkIntervall: 30",
+		"invalid duration":    "checkInterval: soon",
+		"zero interval":       "checkInterval: 0",
+		"invalid port":        "metricsPort: 70000",
+		"invalid mode":        "unknownGaugeMode: sometimes",
+		"negative timeout":    "checks: {nodes: {timeout: -5}}",
+		"unknown check field": "checks: {nodes: {threshold: 1}}",
+		"not yaml":            "checkInterval: [",
+		"invalid threshold":   "nodeNotReadyThresholds: {worker: 150%}",
+		"invalid selector":    "podExcludeSelector: 'app in (a'",
+		"invalid job mode":    "failedJobPods: sometimes",
+		"no rule namespaces":  "namespacePodRules: [{failedJobPods: ignore}]",
+		"bad rule pattern":    "namespacePodRules: [{namespaces: ['openshift-['], failedJobPods: ignore}]",
+		"zero cycles":         "unhealthyCycles: 0",
+		"negative min age":    "operatorConditionMinAges: {etcd: -60}",
+		"unnamed group":       "operatorGroups: [{operators: [etcd]}]",
+		"empty group":         "operatorGroups: [{name: cp}]",
+		"duplicate group":     "operatorGroups: [{name: cp, operators: [etcd]}, {name: cp, operators: [dns]}]",
+		"zero check cycles":   "checks: {nodes: {healthyCycles: 0}}",
+	}
+	for name, content := range cases {
+		t.Run(name, func(t *testing.T) {
+			writeConfigFile(t, content)
+			if _, err := Load(); err == nil {
+				t.Errorf("expected error for %q, got nil", content)
+			}
+		})
+	}
+}
+
+func TestLoad_ConfigFileMissing(t *testing.T) {
+	t.Setenv("CONFIG_FILE", filepath.Join(t.TempDir(), "missing.yaml"))
+
+	if _, err := Load(); err == nil {
+		t.Fatal("expected error for missing CONFIG_FILE, got nil")
+	}
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Hash returns a hash of the effective configuration. It fits in 48 bits so
+// that it is exactly representable as a Prometheus sample value.
+func (c Config) Hash() uint64 {
+	// Marshalling a struct is deterministic: fields in declaration order and
+	// map keys sorted.
+	data, err := json.Marshal(c)
+	if err != nil {
+		return 0
+	}
+	sum := sha256.Sum256(data)
+	return binary.BigEndian.Uint64(sum[:8]) >> 16
+}
+
+// Reloader re-reads the config file and hands out the configuration to use for
+// each check cycle. A new configuration is only adopted once it has been
+// fully validated, so a cycle never sees a partially applied or invalid file.
+type Reloader struct {
+	mu       sync.Mutex
+	current  Config
+	lastData []byte
+}
+
+// NewReloader returns a Reloader that starts from cfg, as returned by Load.
+func NewReloader(cfg Config) *Reloader {
+	r := &Reloader{current: cfg}
+	if cfg.ConfigFile != "" {
+		// Remember the content cfg was loaded from so the first Reload is a no-op.
+		r.lastData, _ = os.ReadFile(cfg.ConfigFile)
+	}
+	return r
+}
+
+// Current returns the configuration currently in effect.
+func (r *Reloader) Current() Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload re-reads the config file if one is configured and returns the
+// configuration to use from now on, and whether it changed. The file is only
+// parsed when its content changed since the previous call.
+//
+// If the new content is invalid, or validate rejects it, Reload returns the
+// previous configuration together with the error; the same content is not
+// reported again. Settings that only take effect at startup (metrics port,
+// watch mode, leader election) keep their startup values, with a warning.
+func (r *Reloader) Reload(validate func(Config) error) (Config, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.current.ConfigFile == "" {
+		return r.current, false, nil
+	}
+
+	data, err := os.ReadFile(r.current.ConfigFile)
+	if err != nil {
+		return r.current, false, fmt.Errorf("CONFIG_FILE: %w", err)
+	}
+	if bytes.Equal(data, r.lastData) {
+		return r.current, false, nil
+	}
+	r.lastData = data
+
+	next, err := load(data)
+	if err != nil {
+		return r.current, false, err
+	}
+	keepStartupSettings(&next, r.current)
+	if next.LeaderElection && next.LeaderElectionLeaseDuration >= next.CheckInterval {
+		return r.current, false, fmt.Errorf("config file %s: checkInterval (%s) must be longer than the leader election lease duration (%s)",
+			next.ConfigFile, next.CheckInterval, next.LeaderElectionLeaseDuration)
+	}
+	if validate != nil {
+		if err := validate(next); err != nil {
+			return r.current, false, fmt.Errorf("config file %s: %w", next.ConfigFile, err)
+		}
+	}
+
+	if next.Hash() == r.current.Hash() {
+		return r.current, false, nil
+	}
+	r.current = next
+	return next, true, nil
+}
+
+// keepStartupSettings copies the settings that cannot change while running
+// from running into next, warning about any the new file tried to change.
+func keepStartupSettings(next *Config, running Config) {
+	warn := func(setting string, changed bool) {
+		if changed {
+			log.Printf("WARNING: Config file changes %s, which only takes effect after a restart — keeping the current value.", setting)
+		}
+	}
+	warn("metricsPort", next.MetricsPort != running.MetricsPort)
+	warn("watchMode", next.WatchMode != running.WatchMode)
+	warn("watchDebounce", next.WatchDebounce != running.WatchDebounce)
+	warn("leaderElection", next.LeaderElection != running.LeaderElection ||
+		next.LeaderElectionNamespace != running.LeaderElectionNamespace ||
+		next.LeaderElectionLeaseName != running.LeaderElectionLeaseName ||
+		next.LeaderElectionLeaseDuration != running.LeaderElectionLeaseDuration)
+
+	next.MetricsPort = running.MetricsPort
+	next.WatchMode = running.WatchMode
+	next.WatchDebounce = running.WatchDebounce
+	next.LeaderElection = running.LeaderElection
+	next.LeaderElectionNamespace = running.LeaderElectionNamespace
+	next.LeaderElectionLeaseName = running.LeaderElectionLeaseName
+	next.LeaderElectionLeaseDuration = running.LeaderElectionLeaseDuration
+}
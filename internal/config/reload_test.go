@@ -0,0 +1,99 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestReloader_NoConfigFile(t *testing.T) {
+	t.Setenv("CONFIG_FILE", "")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+
+	_, changed, err := NewReloader(cfg).Reload(nil)
+	if err != nil || changed {
+		t.Errorf("expected no change without a config file, got changed=%v err=%v", changed, err)
+	}
+}
+
+func TestReloader_AppliesValidChange(t *testing.T) {
+	path := writeConfigFile(t, "checkInterval: 30")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r := NewReloader(cfg)
+
+	if _, changed, _ := r.Reload(nil); changed {
+		t.Error("expected no change before the file is modified")
+	}
+
+	if err := os.WriteFile(path, []byte("checkInterval: 60\nmetricsPort: 9999"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	next, changed, err := r.Reload(nil)
+	if err != nil || !changed {
+		t.Fatalf("expected the change to be applied, got changed=%v err=%v", changed, err)
+	}
+	if next.CheckInterval != time.Minute {
+		t.Errorf("expected CheckInterval=60s, got %v", next.CheckInterval)
+	}
+	if next.MetricsPort != cfg.MetricsPort {
+		t.Errorf("expected MetricsPort to keep its startup value %d, got %d", cfg.MetricsPort, next.MetricsPort)
+	}
+	if next.Hash() == cfg.Hash() {
+		t.Error("expected the config hash to change")
+	}
+	if r.Current().CheckInterval != time.Minute {
+		t.Errorf("expected Current to return the reloaded config, got %v", r.Current().CheckInterval)
+	}
+}
+
+func TestReloader_KeepsConfigOnInvalidChange(t *testing.T) {
+	path := writeConfigFile(t, "checkInterval: 30")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r := NewReloader(cfg)
+
+	if err := os.WriteFile(path, []byte("checkInterval: -1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	current, changed, err := r.Reload(nil)
+	if err == nil || changed {
+		t.Fatalf("expected the invalid file to be rejected, got changed=%v err=%v", changed, err)
+	}
+	if current.Hash() != cfg.Hash() {
+		t.Error("expected the previous config to stay in effect")
+	}
+
+	// The same invalid content is reported once, not on every cycle.
+	if _, _, err := r.Reload(nil); err != nil {
+		t.Errorf("expected unchanged invalid content not to be reported again, got %v", err)
+	}
+}
+
+func TestReloader_ValidateRejects(t *testing.T) {
+	path := writeConfigFile(t, "checkInterval: 30")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	r := NewReloader(cfg)
+
+	if err := os.WriteFile(path, []byte("disabledChecks: [typo]"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, changed, err := r.Reload(func(Config) error { return errors.New("unknown check(s) typo") })
+	if err == nil || changed {
+		t.Fatalf("expected validate to reject the change, got changed=%v err=%v", changed, err)
+	}
+	if len(r.Current().DisabledChecks) != 0 {
+		t.Errorf("expected the previous config to stay in effect, got %v", r.Current().DisabledChecks)
+	}
+}
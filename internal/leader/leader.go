@@ -0,0 +1,156 @@
+// Package leader provides optional Lease-based leader election so that several
+// health-checker replicas can run while only one of them queries the API.
+package leader
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+	"github.com/openshift-cluster-check/health-checker/internal/metrics"
+)
+
+// serviceAccountNamespaceFile holds the pod's namespace when running in-cluster.
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Elector runs a function only while this replica holds the Lease, and
+// reports whether it currently does.
+type Elector struct {
+	elector  *leaderelection.LeaderElector
+	identity string
+	leading  atomic.Bool
+	run      func(ctx context.Context)
+
+	// term is held while run executes, so that the terms of successive
+	// leaderships never overlap.
+	term sync.Mutex
+}
+
+// New returns an Elector that calls run with a leadership-scoped context each
+// time this replica becomes leader. The context is cancelled when leadership
+// is lost; run must return promptly after that.
+func New(client kubernetes.Interface, cfg config.Config, run func(ctx context.Context)) (*Elector, error) {
+	namespace, err := leaseNamespace(cfg)
+	if err != nil {
+		return nil, err
+	}
+	identity, err := identity()
+	if err != nil {
+		return nil, err
+	}
+
+	e := &Elector{identity: identity, run: run}
+
+	lock := &resourcelock.LeaseLock{
+		LeaseMeta:  metav1.ObjectMeta{Name: cfg.LeaderElectionLeaseName, Namespace: namespace},
+		Client:     client.CoordinationV1(),
+		LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+	}
+
+	// Renew well inside the lease and retry often enough that a follower notices
+	// an expired lease quickly; client-go requires
+	// LeaseDuration > RenewDeadline > 1.2 * RetryPeriod.
+	lease := cfg.LeaderElectionLeaseDuration
+	e.elector, err = leaderelection.NewLeaderElector(leaderelection.LeaderElectionConfig{
+		Lock:            lock,
+		LeaseDuration:   lease,
+		RenewDeadline:   lease * 2 / 3,
+		RetryPeriod:     lease / 5,
+		ReleaseOnCancel: true,
+		Name:            cfg.LeaderElectionLeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: e.onStartedLeading,
+			OnStoppedLeading: e.onStoppedLeading,
+			OnNewLeader: func(current string) {
+				if current != identity {
+					log.Printf("INFO: Leader election: %q is the leader, this replica (%q) is on standby.", current, identity)
+				}
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid leader election configuration: %w", err)
+	}
+
+	metrics.Leader.Set(0)
+	log.Printf("INFO: Leader election enabled: lease=%s/%s identity=%q duration=%s", namespace, cfg.LeaderElectionLeaseName, identity, lease)
+	return e, nil
+}
+
+// Run takes part in leader election until ctx is cancelled. After losing
+// leadership it rejoins the election as a follower. It returns once the run
+// function of the last leadership has returned.
+func (e *Elector) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		e.elector.Run(ctx)
+	}
+	e.term.Lock()
+	defer e.term.Unlock()
+}
+
+// IsLeader reports whether this replica currently holds the Lease.
+func (e *Elector) IsLeader() bool {
+	return e.leading.Load()
+}
+
+// onStartedLeading runs e.run for one leadership. client-go calls it in a
+// goroutine and does not wait for it before rejoining the election, so after
+// losing and regaining the Lease the previous leadership's run may still be
+// returning: wait for it, and skip a leadership that has already ended.
+func (e *Elector) onStartedLeading(ctx context.Context) {
+	e.term.Lock()
+	defer e.term.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+
+	log.Printf("INFO: Leader election: this replica (%q) is now the leader.", e.identity)
+	e.leading.Store(true)
+	metrics.Leader.Set(1)
+	defer func() {
+		e.leading.Store(false)
+		metrics.Leader.Set(0)
+	}()
+	e.run(ctx)
+}
+
+func (e *Elector) onStoppedLeading() {
+	log.Printf("INFO: Leader election: this replica (%q) is no longer the leader.", e.identity)
+	e.leading.Store(false)
+	metrics.Leader.Set(0)
+}
+
+// leaseNamespace returns the configured Lease namespace, falling back to the
+// namespace of the pod's service account.
+func leaseNamespace(cfg config.Config) (string, error) {
+	if cfg.LeaderElectionNamespace != "" {
+		return cfg.LeaderElectionNamespace, nil
+	}
+	data, err := os.ReadFile(serviceAccountNamespaceFile)
+	if err != nil {
+		return "", fmt.Errorf("LEADER_ELECTION_NAMESPACE or POD_NAMESPACE must be set when not running in-cluster: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// identity returns a unique name for this replica: POD_NAME if set, otherwise the hostname.
+func identity() (string, error) {
+	if name := os.Getenv("POD_NAME"); name != "" {
+		return name, nil
+	}
+	host, err := os.Hostname()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine leader election identity: %w", err)
+	}
+	return host, nil
+}
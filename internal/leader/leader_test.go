@@ -0,0 +1,128 @@
+package leader
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/openshift-cluster-check/health-checker/internal/config"
+)
+
+func TestLeaseNamespace_Configured(t *testing.T) {
+	ns, err := leaseNamespace(config.Config{LeaderElectionNamespace: "openshift-health-checker"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if ns != "openshift-health-checker" {
+		t.Errorf("expected configured namespace, got %q", ns)
+	}
+}
+
+func TestIdentity_PodName(t *testing.T) {
+	t.Setenv("POD_NAME", "health-checker-7d9f-abcde")
+
+	id, err := identity()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if id != "health-checker-7d9f-abcde" {
+		t.Errorf("expected POD_NAME as identity, got %q", id)
+	}
+}
+
+func TestIdentity_HostnameFallback(t *testing.T) {
+	t.Setenv("POD_NAME", "")
+
+	id, err := identity()
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if id == "" {
+		t.Error("expected hostname as identity, got empty string")
+	}
+}
+
+func TestOnStartedLeading_TermsDoNotOverlap(t *testing.T) {
+	var mu sync.Mutex
+	var events []string
+	record := func(event string) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, event)
+	}
+
+	// The first term's cycle is still in progress when leadership is lost,
+	// and only finishes (and resets) once finish is closed.
+	started := make(chan struct{})
+	finish := make(chan struct{})
+	terms := 0
+	e := &Elector{identity: "test"}
+	e.run = func(ctx context.Context) {
+		terms++
+		if terms == 1 {
+			record("start 1")
+			close(started)
+			<-ctx.Done()
+			<-finish
+			record("reset 1")
+			return
+		}
+		record("start 2")
+		<-ctx.Done()
+		record("reset 2")
+	}
+
+	ctx1, cancel1 := context.WithCancel(context.Background())
+	done1 := make(chan struct{})
+	go func() {
+		defer close(done1)
+		e.onStartedLeading(ctx1)
+	}()
+	<-started
+	cancel1()
+
+	// Leadership is regained while the first term is still running.
+	ctx2, cancel2 := context.WithCancel(context.Background())
+	defer cancel2()
+	done2 := make(chan struct{})
+	go func() {
+		defer close(done2)
+		e.onStartedLeading(ctx2)
+	}()
+
+	// A term that already ended before it got to run is skipped.
+	ctx3, cancel3 := context.WithCancel(context.Background())
+	cancel3()
+	go e.onStartedLeading(ctx3)
+
+	time.Sleep(50 * time.Millisecond)
+	mu.Lock()
+	if !slices.Equal(events, []string{"start 1"}) {
+		t.Errorf("expected the second term to wait for the first, got %v", events)
+	}
+	mu.Unlock()
+
+	close(finish)
+	<-done1
+	deadline := time.After(5 * time.Second)
+	for !e.IsLeader() {
+		select {
+		case <-deadline:
+			t.Fatal("expected the second term to start after the first returned")
+		case <-time.After(time.Millisecond):
+		}
+	}
+	cancel2()
+	<-done2
+
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{"start 1", "reset 1", "start 2", "reset 2"}; !slices.Equal(events, want) {
+		t.Errorf("expected events %v, got %v", want, events)
+	}
+	if e.IsLeader() {
+		t.Error("expected IsLeader=false after the last term returned")
+	}
+}
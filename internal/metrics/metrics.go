@@ -1,10 +1,16 @@
-// Package metrics defines and registers the five Prometheus binary gauge metrics
-// exposed by the OpenShift cluster health-checker.
+// Package metrics defines and registers the Prometheus metrics exposed by the
+// OpenShift cluster health-checker: one binary gauge per check plus
+// per-object labelled gauges that identify what is unhealthy.
 package metrics
 
-import "github.com/prometheus/client_golang/prometheus"
+import (
+	"strings"
+	"sync"
 
-// All five binary gauges: 0 = healthy, 1 = unhealthy.
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Binary gauges, one per check: 0 = healthy, 1 = unhealthy.
 var (
 	// ClusterOperatorsDegraded is set to 1 if any ClusterOperator (excluding etcd)
 	// has Degraded=True or Available=False.
@@ -13,10 +19,11 @@ var (
 		Help: "1 if any ClusterOperator (excluding etcd) is degraded or unavailable, 0 otherwise.",
 	})
 
-	// NodesNotReady is set to 1 if any Node has condition Ready != True.
+	// NodesNotReady is set to 1 if any node role has more Nodes with condition
+	// Ready != True than its threshold allows (by default: any such Node).
 	NodesNotReady = prometheus.NewGauge(prometheus.GaugeOpts{
 		Name: "openshift_nodes_not_ready",
-		Help: "1 if any Node has condition Ready != True, 0 otherwise.",
+		Help: "1 if any node role has more Nodes with condition Ready != True than its threshold allows (by default any), 0 otherwise.",
 	})
 
 	// SystemPodsFailing is set to 1 if any pod in a system namespace has
@@ -39,9 +46,290 @@ var (
 		Name: "openshift_etcd_degraded",
 		Help: "1 if the etcd ClusterOperator is degraded or unavailable, 0 otherwise.",
 	})
+
+	// MachineConfigPoolsDegraded is set to 1 if any MachineConfigPool has
+	// Degraded=True, NodeDegraded=True or RenderDegraded=True.
+	MachineConfigPoolsDegraded = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_machineconfigpools_degraded",
+		Help: "1 if any MachineConfigPool is Degraded, NodeDegraded or RenderDegraded, 0 otherwise.",
+	})
+
+	// CertificatesExpiring is set to 1 if any checked certificate expires
+	// within the configured threshold (or has already expired).
+	CertificatesExpiring = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_certificates_expiring",
+		Help: "1 if any platform TLS Secret (or kubelet serving certificate, if enabled) expires within the configured threshold, 0 otherwise.",
+	})
+
+	// NodesImpaired is set to 1 if any Node has one of the configured node
+	// problems (pressure, NetworkUnavailable, cordoned or a configured taint).
+	// It is independent of NodesNotReady.
+	NodesImpaired = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_nodes_impaired",
+		Help: "1 if any Node is under pressure, has NetworkUnavailable, is cordoned or has a configured taint, 0 otherwise.",
+	})
+
+	// SystemPodsStuck is set to 1 if any pod in a system namespace has been
+	// unschedulable or unable to start its containers for longer than allowed.
+	SystemPodsStuck = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_system_pods_stuck",
+		Help: "1 if any pod in a system namespace has been Unschedulable, ContainerCreating, ImagePullBackOff, ErrImagePull or CreateContainerConfigError for longer than allowed, 0 otherwise.",
+	})
+
+	// SystemWorkloadsUnavailable is set to 1 if any Deployment, DaemonSet or
+	// StatefulSet in a system namespace is unavailable or not progressing.
+	SystemWorkloadsUnavailable = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_system_workloads_unavailable",
+		Help: "1 if any Deployment, DaemonSet or StatefulSet in a system namespace is unavailable or not progressing, 0 otherwise.",
+	})
+
+	// CSRPendingTooLong is set to 1 if any CertificateSigningRequest has been
+	// pending (neither approved nor denied) for longer than the configured age.
+	CSRPendingTooLong = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_csr_pending_too_long",
+		Help: "1 if any CertificateSigningRequest has been pending longer than the configured age, 0 otherwise.",
+	})
+
+	// ClusterOperatorsProgressingStuck is set to 1 if any ClusterOperator has
+	// been Progressing=True for longer than the configured age.
+	ClusterOperatorsProgressingStuck = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operators_progressing_stuck",
+		Help: "1 if any ClusterOperator has been Progressing=True longer than the configured age, 0 otherwise.",
+	})
+
+	// ClusterUpgradeBlocked is set to 1 if any ClusterOperator has
+	// Upgradeable=False, which blocks the next minor version upgrade.
+	ClusterUpgradeBlocked = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "openshift_cluster_upgrade_blocked",
+		Help: "1 if any ClusterOperator has Upgradeable=False, blocking minor version upgrades, 0 otherwise.",
+	})
+)
+
+// Per-object labelled gauges. Series are removed when the object disappears
+// (or, for failure-only gauges, recovers); see Series.
+var (
+	// ClusterOperatorCondition is 1 if the named condition of a ClusterOperator
+	// is True, 0 otherwise. Populated for the Degraded, Available, Progressing
+	// and Upgradeable conditions.
+	ClusterOperatorCondition = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operator_condition",
+		Help: "1 if the ClusterOperator condition is True, 0 otherwise.",
+	}, []string{"name", "condition"})
+
+	// ClusterOperatorConditionSeconds is how long the named condition of a
+	// ClusterOperator has had its current status, from its lastTransitionTime.
+	// Populated for the same conditions as ClusterOperatorCondition.
+	ClusterOperatorConditionSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operator_condition_seconds",
+		Help: "Seconds since the ClusterOperator condition last changed status.",
+	}, []string{"name", "condition"})
+
+	// ClusterOperatorConditionInfo is 1 for every Degraded=True or
+	// Available=False condition of a ClusterOperator, labelled with the
+	// condition's reason and message (the message is shortened and flattened to
+	// a single line), so alerts can carry the operator's own explanation. Only
+	// such conditions have a series.
+	ClusterOperatorConditionInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operator_condition_info",
+		Help: "1 for every failing ClusterOperator condition (Degraded=True or Available=False), with the condition's reason and message.",
+	}, []string{"name", "condition", "status", "reason", "message"})
+
+	// NodeReady is 1 if a Node has Ready=True, 0 otherwise. The role label is
+	// the comma-separated, sorted list of node-role.kubernetes.io/* labels.
+	NodeReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_node_ready",
+		Help: "1 if the Node has condition Ready=True, 0 otherwise.",
+	}, []string{"node", "role"})
+
+	// NodeRoleNotReady is 1 for a node role (see the nodes check) whose
+	// NotReady nodes exceed the configured threshold, 0 otherwise.
+	NodeRoleNotReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_node_role_not_ready",
+		Help: "1 if more Nodes of the role are NotReady than the configured threshold allows, 0 otherwise.",
+	}, []string{"role"})
+
+	// NodeRoleNodes is the number of Nodes of a role by state (total, not_ready).
+	NodeRoleNodes = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_node_role_nodes",
+		Help: "Number of Nodes of the role, by state (total, not_ready).",
+	}, []string{"role", "state"})
+
+	// NodeProblem is 1 for every problem found on a Node. reason is the
+	// condition (e.g. MemoryPressure), Unschedulable, or "Taint:" followed by
+	// the taint key. Only current problems have a series.
+	NodeProblem = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_node_problem",
+		Help: "1 for every current problem on a Node (pressure condition, NetworkUnavailable, Unschedulable or a configured taint).",
+	}, []string{"node", "reason"})
+
+	// SystemPodFailing is 1 for every failing pod in a system namespace.
+	// Only failing pods have a series; it is removed once the pod recovers or is deleted.
+	SystemPodFailing = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_system_pod_failing",
+		Help: "1 for each failing pod in a system/platform namespace, labelled with the failure reason.",
+	}, []string{"namespace", "pod", "reason"})
+
+	// SystemContainerRestarts is the number of restarts of a system container
+	// within the configured window. Only containers at or above the restart
+	// threshold have a series.
+	SystemContainerRestarts = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_system_container_restarts_in_window",
+		Help: "Restarts of a container in a system namespace within the configured window, for containers at or above the restart threshold.",
+	}, []string{"namespace", "pod", "container"})
+
+	// SystemPodStuckSeconds is how long a stuck pod in a system namespace has
+	// been in its state. Only stuck pods have a series; it is removed once the
+	// pod starts or is deleted.
+	SystemPodStuckSeconds = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_system_pod_stuck_seconds",
+		Help: "Seconds a stuck pod in a system namespace has been in its state (reason), for pods stuck longer than allowed.",
+	}, []string{"namespace", "pod", "reason"})
+
+	// SystemWorkloadUnavailable is 1 for every unavailable or non-progressing
+	// Deployment, DaemonSet or StatefulSet in a system namespace. Only failing
+	// controllers have a series; it is removed once the controller recovers.
+	SystemWorkloadUnavailable = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_system_workload_unavailable",
+		Help: "1 for every unavailable or non-progressing Deployment, DaemonSet or StatefulSet in a system namespace.",
+	}, []string{"namespace", "kind", "name", "reason"})
+
+	// MachineConfigPoolMachines is the number of machines in a MachineConfigPool
+	// by state: total, updated, ready, unavailable and degraded, as reported in
+	// the pool's status.
+	MachineConfigPoolMachines = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_machineconfigpool_machines",
+		Help: "Number of machines in the MachineConfigPool by state (total, updated, ready, unavailable, degraded).",
+	}, []string{"pool", "state"})
+
+	// TLSSecretExpiry is the expiry time of the certificate that expires first
+	// in each kubernetes.io/tls Secret in a system namespace.
+	TLSSecretExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_tls_secret_expiry_timestamp_seconds",
+		Help: "Unix time at which the soonest-expiring certificate in the TLS Secret expires.",
+	}, []string{"namespace", "secret"})
+
+	// KubeletCertExpiry is the expiry time of each node's kubelet serving
+	// certificate. Only populated when kubelet certificate probing is enabled.
+	KubeletCertExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_kubelet_serving_cert_expiry_timestamp_seconds",
+		Help: "Unix time at which the node's kubelet serving certificate expires.",
+	}, []string{"node"})
+
+	// CSRPendingTooLongCount is the number of CertificateSigningRequests pending
+	// longer than the configured age, by signer. Only signers with such CSRs
+	// have a series.
+	CSRPendingTooLongCount = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_csr_pending_too_long_count",
+		Help: "Number of CertificateSigningRequests pending longer than the configured age, by signer.",
+	}, []string{"signer"})
+
+	// ClusterOperatorGroupDegraded is 1 if any ClusterOperator of a
+	// user-defined operator group is degraded or unavailable, 0 otherwise. Every
+	// configured group has a series, driven by the group's check like a binary
+	// gauge; severity is the group's configured severity.
+	ClusterOperatorGroupDegraded = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operator_group_degraded",
+		Help: "1 if any ClusterOperator of the operator group has Degraded=True or Available=False, 0 otherwise.",
+	}, []string{"group", "severity"})
+
+	// ClusterOperatorProgressingStuck is the number of seconds a ClusterOperator
+	// has been Progressing=True. Only operators stuck longer than the
+	// configured age have a series.
+	ClusterOperatorProgressingStuck = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operator_progressing_stuck_seconds",
+		Help: "Seconds a ClusterOperator has been Progressing=True, for operators progressing longer than the configured age.",
+	}, []string{"name"})
+
+	// ClusterOperatorUpgradeBlocked is 1 for every ClusterOperator with
+	// Upgradeable=False, labelled with the condition's reason and message (the
+	// message is shortened and flattened to a single line). Only such operators
+	// have a series.
+	ClusterOperatorUpgradeBlocked = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "openshift_cluster_operator_upgrade_blocked",
+		Help: "1 if the ClusterOperator has Upgradeable=False, with the condition's reason and message.",
+	}, []string{"name", "reason", "message"})
+)
+
+// Self-observability metrics for the checker itself. They distinguish "the
+// cluster is broken" (binary gauge = 1, no errors) from "the checker cannot
+// see the cluster" (errors increasing, last success timestamp going stale).
+var (
+	// CheckDuration observes how long each check run takes, including runs
+	// that time out.
+	CheckDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "health_checker_check_duration_seconds",
+		Help:    "Duration of each health check run in seconds.",
+		Buckets: []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"check"})
+
+	// CheckErrors counts check runs that could not evaluate the cluster,
+	// labelled with a short error classification (e.g. timeout, forbidden, api_error).
+	CheckErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "health_checker_check_errors_total",
+		Help: "Total number of health check runs that failed to evaluate the cluster, by check and reason.",
+	}, []string{"check", "reason"})
+
+	// CheckLastSuccess is the Unix time of the last run of each check that
+	// evaluated the cluster without error, whether it found it healthy or not.
+	CheckLastSuccess = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_checker_last_success_timestamp_seconds",
+		Help: "Unix timestamp of the last health check run that completed without error.",
+	}, []string{"check"})
+
+	// CheckUnknown is 1 if the latest result of a check is unknown, i.e. the
+	// check could not evaluate the cluster (API error, timeout, panic).
+	CheckUnknown = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_checker_check_unknown",
+		Help: "1 if the latest run of the health check could not determine cluster health, 0 otherwise.",
+	}, []string{"check"})
+
+	// CheckRawUnhealthy is 1 if the latest result of a check is unhealthy. It
+	// follows every result, whereas the check's binary gauge only changes
+	// after the number of consecutive results set by the check's hysteresis.
+	// Unknown results leave it unchanged.
+	CheckRawUnhealthy = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "health_checker_check_raw_unhealthy",
+		Help: "1 if the latest run of the health check found the cluster unhealthy, before hysteresis; 0 if it found it healthy.",
+	}, []string{"check"})
+
+	// CheckFlaps counts changes of a check's result between healthy and
+	// unhealthy, whether or not they changed the binary gauge. Unknown results
+	// are skipped.
+	CheckFlaps = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "health_checker_check_flaps_total",
+		Help: "Total number of times the result of the health check changed between healthy and unhealthy.",
+	}, []string{"check"})
+
+	// Leader is 1 while this replica runs the checks and 0 while it is a
+	// standby follower under leader election. Always 1 without leader election.
+	Leader = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "health_checker_leader",
+		Help: "1 if this replica is the active leader running health checks, 0 if it is a standby follower.",
+	})
+
+	// CyclesTotal counts completed check cycles. Skipped (overlapping) cycles
+	// are not counted.
+	CyclesTotal = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "health_checker_cycles_total",
+		Help: "Total number of completed health check cycles.",
+	})
+
+	// ConfigHash identifies the configuration in effect, so a config file change
+	// can be confirmed as applied (and correlated with behaviour changes).
+	ConfigHash = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "health_checker_config_hash",
+		Help: "Hash of the configuration currently in effect.",
+	})
+
+	// ConfigReloadFailures counts config file changes that were rejected; the
+	// previous configuration stays in effect.
+	ConfigReloadFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "health_checker_config_reload_failures_total",
+		Help: "Total number of config file reloads rejected as invalid.",
+	})
 )
 
-// Register registers all five gauges with the default Prometheus registry.
+// Register registers all metrics with the default Prometheus registry.
 // This should be called once at startup before the /metrics endpoint is served.
 func Register() {
 	prometheus.MustRegister(
@@ -50,5 +338,93 @@ func Register() {
 		SystemPodsFailing,
 		ClusterVersionDegraded,
 		EtcdDegraded,
+		MachineConfigPoolsDegraded,
+		CertificatesExpiring,
+		NodesImpaired,
+		SystemPodsStuck,
+		SystemWorkloadsUnavailable,
+		CSRPendingTooLong,
+		ClusterOperatorsProgressingStuck,
+		ClusterUpgradeBlocked,
+		ClusterOperatorCondition,
+		ClusterOperatorConditionSeconds,
+		ClusterOperatorConditionInfo,
+		NodeReady,
+		NodeRoleNotReady,
+		NodeRoleNodes,
+		NodeProblem,
+		SystemPodFailing,
+		SystemContainerRestarts,
+		SystemPodStuckSeconds,
+		SystemWorkloadUnavailable,
+		MachineConfigPoolMachines,
+		TLSSecretExpiry,
+		KubeletCertExpiry,
+		CSRPendingTooLongCount,
+		ClusterOperatorGroupDegraded,
+		ClusterOperatorProgressingStuck,
+		ClusterOperatorUpgradeBlocked,
+		CheckDuration,
+		CheckErrors,
+		CheckLastSuccess,
+		CheckUnknown,
+		CheckRawUnhealthy,
+		CheckFlaps,
+		Leader,
+		CyclesTotal,
+		ConfigHash,
+		ConfigReloadFailures,
 	)
 }
+
+// Sample is one labelled value to be written to a GaugeVec.
+// Labels are given in the order the GaugeVec declares them.
+type Sample struct {
+	Labels []string
+	Value  float64
+}
+
+// Series tracks the series a single writer has set on a GaugeVec, so that
+// series not refreshed by the writer's latest Update are deleted. Several
+// writers may share one GaugeVec as long as they write disjoint label sets.
+type Series struct {
+	vec *prometheus.GaugeVec
+
+	mu   sync.Mutex
+	last map[string][]string
+}
+
+// NewSeries returns a Series that writes to vec.
+func NewSeries(vec *prometheus.GaugeVec) *Series {
+	return &Series{vec: vec, last: map[string][]string{}}
+}
+
+// Update sets every sample and deletes the series written by the previous
+// Update that are not present in samples.
+func (s *Series) Update(samples []Sample) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := make(map[string][]string, len(samples))
+	for _, sample := range samples {
+		s.vec.WithLabelValues(sample.Labels...).Set(sample.Value)
+		current[seriesKey(sample.Labels)] = sample.Labels
+	}
+	for key, labels := range s.last {
+		if _, ok := current[key]; !ok {
+			s.vec.DeleteLabelValues(labels...)
+		}
+	}
+	s.last = current
+}
+
+// Reset deletes every series written by the previous Update, e.g. when the
+// writer stops running.
+func (s *Series) Reset() {
+	s.Update(nil)
+}
+
+// seriesKey joins label values with a separator that cannot appear in valid UTF-8.
+func seriesKey(labels []string) string {
+	return strings.Join(labels, "\xff")
+}
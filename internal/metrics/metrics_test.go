@@ -0,0 +1,47 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func newTestVec() *prometheus.GaugeVec {
+	return prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: "test", Help: "test"}, []string{"name"})
+}
+
+func TestSeries_UpdateRemovesStale(t *testing.T) {
+	vec := newTestVec()
+	s := NewSeries(vec)
+
+	s.Update([]Sample{{Labels: []string{"a"}, Value: 1}, {Labels: []string{"b"}, Value: 0}})
+	if got := testutil.CollectAndCount(vec); got != 2 {
+		t.Fatalf("expected 2 series, got %d", got)
+	}
+
+	s.Update([]Sample{{Labels: []string{"b"}, Value: 1}})
+	if got := testutil.CollectAndCount(vec); got != 1 {
+		t.Fatalf("expected stale series to be removed, got %d series", got)
+	}
+	if got := testutil.ToFloat64(vec.WithLabelValues("b")); got != 1 {
+		t.Errorf("expected b=1, got %v", got)
+	}
+}
+
+func TestSeries_WritersDoNotRemoveEachOther(t *testing.T) {
+	vec := newTestVec()
+	first := NewSeries(vec)
+	second := NewSeries(vec)
+
+	first.Update([]Sample{{Labels: []string{"a"}, Value: 1}})
+	second.Update([]Sample{{Labels: []string{"b"}, Value: 1}})
+	second.Update(nil)
+
+	if got := testutil.CollectAndCount(vec); got != 1 {
+		t.Fatalf("expected only the first writer's series to remain, got %d series", got)
+	}
+	if got := testutil.ToFloat64(vec.WithLabelValues("a")); got != 1 {
+		t.Errorf("expected a=1, got %v", got)
+	}
+}